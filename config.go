@@ -0,0 +1,413 @@
+package sparalog
+
+// Configurazione dichiarativa di writer e livelli.
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/modulo-srl/sparalog/logs"
+)
+
+// Variabili d'ambiente che, se definite, sovrascrivono le relative voci della configurazione.
+const (
+	// Livelli mutati, separati da virgola (es. "debug,info"; vuota = nessuno).
+	EnvMute = "SPARALOG_MUTE"
+	// Livelli con stacktrace, separati da virgola (es. "fatal,error"; vuota = nessuno).
+	EnvStackTrace = "SPARALOG_STACKTRACE"
+	// Regole di livello dei logger con nome (vedi logs.SetLoggersLevels()).
+	EnvLevels = "SPARALOG_LEVELS"
+)
+
+// Configurazione del sistema di logging, tipicamente letta da JSON:
+//
+//	{
+//		"writers": {
+//			"main": {"type": "file", "format": "json", "params": {"filename": "/var/log/app.log"}},
+//			"console": {"type": "stdout", "levels": ["debug"]},
+//			"alerts": {"type": "syslog", "levels": ["fatal", "error"], "params": {"tag": "app"}}
+//		},
+//		"default": "main",
+//		"mute": ["debug"],
+//		"stacktrace": ["fatal", "error"],
+//		"levels": "db/*=warning"
+//	}
+//
+// Le voci mute e stacktrace omesse lasciano invariata la relativa impostazione,
+// mentre levels omessa rimuove le regole di livello impostate in precedenza.
+type Config struct {
+	// Writer per nome; il nome viene impostato come nome descrittivo del writer.
+	Writers map[string]WriterConfig `json:"writers"`
+
+	// Nome del writer di default, associato a tutti i livelli
+	// (riceve le eventuali loggate di errore o di feedback da parte degli altri writer).
+	// Se omesso viene usato un writer di tipo stdout.
+	Default string `json:"default"`
+
+	// Livelli mutati (nil = invariati).
+	Mute []string `json:"mute"`
+
+	// Livelli con stacktrace (nil = invariati).
+	StackTrace []string `json:"stacktrace"`
+
+	// Regole di livello dei logger con nome (vedi logs.SetLoggersLevels(); vuota = nessuna regola).
+	Levels string `json:"levels"`
+}
+
+// Configurazione di un writer.
+type WriterConfig struct {
+	// Tipo di writer, tra quelli registrati con RegisterWriterType().
+	Type string `json:"type"`
+
+	// Livelli a cui associare il writer (vuoto = tutti).
+	Levels []string `json:"levels"`
+
+	// Formato delle loggate: "text", "logfmt" o "json" (vuoto = quello del writer).
+	Format string `json:"format"`
+
+	// Parametri specifici del tipo di writer.
+	Params json.RawMessage `json:"params"`
+}
+
+// Legge la configurazione in formato JSON e la applica al logger di default,
+// sovrascrivendola con le eventuali variabili d'ambiente (vedi EnvMute, EnvStackTrace, EnvLevels).
+// I writer non più presenti vengono disassociati e stoppati.
+func Configure(r io.Reader) error {
+	cfg, err := decodeConfig(r)
+	if err != nil {
+		return err
+	}
+
+	return ApplyConfig(cfg)
+}
+
+// Applica la configurazione al logger di default,
+// sovrascrivendola con le eventuali variabili d'ambiente (vedi EnvMute, EnvStackTrace, EnvLevels).
+// La configurazione viene validata interamente prima di essere applicata,
+// e i writer vengono sostituiti atomicamente (vedi logs.ReplaceWriters()):
+// quelli con tipo, formato e parametri invariati rispetto alla configurazione precedente
+// vengono mantenuti senza essere riavviati, quelli non più presenti vengono stoppati.
+func ApplyConfig(cfg *Config) error {
+	configState.mu.Lock()
+	defer configState.mu.Unlock()
+
+	return applyConfig(cfg)
+}
+
+func decodeConfig(r io.Reader) (*Config, error) {
+	var cfg Config
+
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+
+	err := dec.Decode(&cfg)
+	if err != nil {
+		return nil, fmt.Errorf("config: %w", err)
+	}
+
+	return &cfg, nil
+}
+
+// Va invocata con configState.mu acquisito.
+func applyConfig(cfg *Config) error {
+	cfg = applyEnv(cfg)
+
+	mute, err := parseLevels(cfg.Mute)
+	if err != nil {
+		return fmt.Errorf("config: mute: %w", err)
+	}
+
+	stackTrace, err := parseLevels(cfg.StackTrace)
+	if err != nil {
+		return fmt.Errorf("config: stacktrace: %w", err)
+	}
+
+	err = logs.ValidateLoggersLevels(cfg.Levels)
+	if err != nil {
+		return fmt.Errorf("config: levels: %w", err)
+	}
+
+	ww, err := newConfigWriters(cfg, configState.writers)
+	if err != nil {
+		return err
+	}
+
+	err = logs.ReplaceWriters(ww.defaultW, ww.routes)
+	if err != nil {
+		ww.stopCreated()
+		return fmt.Errorf("config: %w", err)
+	}
+
+	configState.writers = ww.writers
+
+	if cfg.Mute != nil {
+		for _, level := range logs.Levels {
+			logs.Mute(level, containsLevel(mute, level))
+		}
+	}
+
+	if cfg.StackTrace != nil {
+		logs.EnableLevelsStackTrace(stackTrace)
+	}
+
+	err = logs.SetLoggersLevels(cfg.Levels)
+	if err != nil {
+		return fmt.Errorf("config: levels: %w", err)
+	}
+
+	return nil
+}
+
+// Ritorna una copia della configurazione con le variabili d'ambiente applicate.
+func applyEnv(cfg *Config) *Config {
+	c := *cfg
+
+	if v, ok := os.LookupEnv(EnvMute); ok {
+		c.Mute = splitList(v)
+	}
+
+	if v, ok := os.LookupEnv(EnvStackTrace); ok {
+		c.StackTrace = splitList(v)
+	}
+
+	if v, ok := os.LookupEnv(EnvLevels); ok {
+		c.Levels = v
+	}
+
+	return &c
+}
+
+// Stato dell'ultima configurazione applicata.
+var configState struct {
+	mu sync.Mutex
+
+	// File da rileggere con Reload() (vedi ConfigureFile()).
+	filename string
+
+	// Writer istanziati per nome.
+	writers map[string]*configuredWriter
+}
+
+// Dimentica la configurazione applicata, al reset del logger di default.
+func resetConfigState() {
+	configState.mu.Lock()
+	defer configState.mu.Unlock()
+
+	configState.filename = ""
+	configState.writers = nil
+}
+
+// Nome con cui viene memorizzato il writer di default implicito.
+const implicitDefaultWriter = ""
+
+type configuredWriter struct {
+	// Tipo, formato e parametri: se invariati il writer viene riutilizzato.
+	key string
+
+	w logs.Writer
+}
+
+// Writer della configurazione da applicare.
+type configWriters struct {
+	defaultW logs.Writer
+	routes   []logs.WriterRoute
+
+	writers map[string]*configuredWriter
+
+	// Writer istanziati ex novo (non riutilizzati dalla configurazione precedente).
+	created []logs.Writer
+}
+
+// Istanzia i writer della configurazione, riutilizzando quelli invariati di prev;
+// in caso di errore stoppa quelli già istanziati.
+func newConfigWriters(cfg *Config, prev map[string]*configuredWriter) (*configWriters, error) {
+	ww := &configWriters{
+		writers: make(map[string]*configuredWriter, len(cfg.Writers)+1),
+	}
+
+	if cfg.Default != "" {
+		if _, ok := cfg.Writers[cfg.Default]; !ok {
+			return nil, fmt.Errorf("config: default writer %q not defined", cfg.Default)
+		}
+	}
+
+	names := make([]string, 0, len(cfg.Writers))
+	for name := range cfg.Writers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		wc := cfg.Writers[name]
+
+		if name == implicitDefaultWriter {
+			ww.stopCreated()
+			return nil, fmt.Errorf("config: empty writer name")
+		}
+
+		levels, err := parseLevels(wc.Levels)
+		if err != nil {
+			ww.stopCreated()
+			return nil, fmt.Errorf("config: writer %q: %w", name, err)
+		}
+
+		if name == cfg.Default && len(levels) > 0 {
+			ww.stopCreated()
+			return nil, fmt.Errorf("config: writer %q: the default writer is associated to all levels", name)
+		}
+
+		key := writerKey(&wc)
+
+		cw := prev[name]
+		if cw == nil || cw.key != key {
+			w, err := newWriter(name, &wc)
+			if err != nil {
+				ww.stopCreated()
+				return nil, fmt.Errorf("config: writer %q: %w", name, err)
+			}
+
+			cw = &configuredWriter{key: key, w: w}
+			ww.created = append(ww.created, w)
+		}
+
+		ww.writers[name] = cw
+
+		if name == cfg.Default {
+			ww.defaultW = cw.w
+			continue
+		}
+
+		route := logs.WriterRoute{Writer: cw.w}
+		if len(levels) > 0 {
+			route.Levels = levels
+		}
+		ww.routes = append(ww.routes, route)
+	}
+
+	if cfg.Default == "" {
+		cw := prev[implicitDefaultWriter]
+		if cw == nil {
+			w := newDefaultWriter()
+			cw = &configuredWriter{w: w}
+			ww.created = append(ww.created, w)
+		}
+
+		ww.writers[implicitDefaultWriter] = cw
+		ww.defaultW = cw.w
+	}
+
+	return ww, nil
+}
+
+// Stoppa i writer istanziati ex novo, liberandone le risorse.
+func (ww *configWriters) stopCreated() {
+	for _, w := range ww.created {
+		w.Stop()
+	}
+}
+
+// Ritorna la chiave che identifica la configurazione di un writer, a meno dei livelli.
+func writerKey(wc *WriterConfig) string {
+	var params bytes.Buffer
+
+	if json.Compact(&params, wc.Params) != nil {
+		params.Write(wc.Params)
+	}
+
+	return wc.Type + "\x00" + wc.Format + "\x00" + params.String()
+}
+
+// Istanzia un writer tramite la factory del suo tipo.
+func newWriter(name string, wc *WriterConfig) (logs.Writer, error) {
+	factory, err := writerFactory(wc.Type)
+	if err != nil {
+		return nil, err
+	}
+
+	var formatter logs.Formatter
+
+	if wc.Format != "" {
+		formatter, err = newFormatter(wc.Format)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	w, err := factory(wc.Params)
+	if err != nil {
+		return nil, err
+	}
+
+	if formatter != nil {
+		fw, ok := w.(interface{ SetFormatter(logs.Formatter) })
+		if !ok {
+			w.Stop()
+			return nil, fmt.Errorf("writer type %q does not support formats", wc.Type)
+		}
+		fw.SetFormatter(formatter)
+	}
+
+	if nw, ok := w.(interface{ SetName(string) }); ok {
+		nw.SetName(name)
+	}
+
+	return w, nil
+}
+
+func newFormatter(format string) (logs.Formatter, error) {
+	switch format {
+	case "text":
+		return logs.NewTextFormatter(), nil
+	case "logfmt":
+		return logs.NewLogfmtFormatter(), nil
+	case "json":
+		return logs.NewJSONFormatter(), nil
+	}
+
+	return nil, fmt.Errorf("unknown format %q", format)
+}
+
+func parseLevels(names []string) ([]logs.Level, error) {
+	levels := make([]logs.Level, 0, len(names))
+
+	for _, name := range names {
+		level, err := logs.ParseLevel(name)
+		if err != nil {
+			return nil, err
+		}
+
+		levels = append(levels, level)
+	}
+
+	return levels, nil
+}
+
+func containsLevel(levels []logs.Level, level logs.Level) bool {
+	for _, l := range levels {
+		if l == level {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Suddivide una lista separata da virgole, ignorando gli elementi vuoti.
+func splitList(s string) []string {
+	list := []string{}
+
+	for _, item := range strings.Split(s, ",") {
+		item = strings.TrimSpace(item)
+		if item != "" {
+			list = append(list, item)
+		}
+	}
+
+	return list
+}
@@ -0,0 +1,97 @@
+package sparalog
+
+// Ricaricamento della configurazione a caldo.
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/modulo-srl/sparalog/logs"
+)
+
+// Legge la configurazione dal file JSON specificato e la applica (vedi Configure()),
+// memorizzandone il percorso per i successivi Reload().
+func ConfigureFile(filename string) error {
+	configState.mu.Lock()
+	defer configState.mu.Unlock()
+
+	err := applyConfigFile(filename)
+	if err != nil {
+		return err
+	}
+
+	configState.filename = filename
+
+	return nil
+}
+
+// Rilegge il file di configurazione impostato con ConfigureFile() e lo applica,
+// invocabile anche a logging in corso.
+// Vengono avviati solo i writer nuovi o modificati e stoppati (gentilmente) solo quelli rimossi
+// o modificati, senza perdere gli item in transito; quelli invariati continuano a ricevere item.
+// Se la nuova configurazione non è valida quella corrente rimane in uso.
+func Reload() error {
+	configState.mu.Lock()
+	defer configState.mu.Unlock()
+
+	if configState.filename == "" {
+		return errors.New("config: no configuration file to reload")
+	}
+
+	return applyConfigFile(configState.filename)
+}
+
+// Invoca Reload() alla ricezione dei segnali specificati (SIGHUP se omessi),
+// loggando come errore l'eventuale fallimento.
+// Ritorna una funzione che interrompe la gestione dei segnali.
+func ReloadOnSignal(sigs ...os.Signal) (stop func()) {
+	if len(sigs) == 0 {
+		sigs = []os.Signal{syscall.SIGHUP}
+	}
+
+	ch := make(chan os.Signal, 1)
+	done := make(chan struct{})
+
+	signal.Notify(ch, sigs...)
+
+	go func() {
+		for {
+			select {
+			case <-ch:
+				err := Reload()
+				if err != nil {
+					logs.Errorf("reload failed: %s", err)
+				} else {
+					logs.Info("configuration reloaded")
+				}
+
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return func() {
+		signal.Stop(ch)
+		close(done)
+	}
+}
+
+// Va invocata con configState.mu acquisito.
+func applyConfigFile(filename string) error {
+	f, err := os.Open(filename)
+	if err != nil {
+		return fmt.Errorf("config: %w", err)
+	}
+	defer f.Close()
+
+	cfg, err := decodeConfig(f)
+	if err != nil {
+		return err
+	}
+
+	return applyConfig(cfg)
+}
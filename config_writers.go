@@ -0,0 +1,167 @@
+package sparalog
+
+// Registro dei tipi di writer istanziabili dalla configurazione.
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/modulo-srl/sparalog/logs"
+	"github.com/modulo-srl/sparalog/writers"
+)
+
+// Istanzia un writer a partire dai parametri della configurazione
+// (eventualmente vuoti, se omessi).
+type WriterFactory func(params json.RawMessage) (logs.Writer, error)
+
+var (
+	writerTypesMu sync.RWMutex
+	writerTypes   = map[string]WriterFactory{}
+)
+
+// Registra un tipo di writer istanziabile dalla configurazione,
+// sostituendo l'eventuale tipo omonimo.
+// Tipi predefiniti: stdout, file, file_rotate, syslog, tcp, telegram.
+func RegisterWriterType(name string, factory WriterFactory) {
+	writerTypesMu.Lock()
+	defer writerTypesMu.Unlock()
+
+	writerTypes[name] = factory
+}
+
+func writerFactory(name string) (WriterFactory, error) {
+	writerTypesMu.RLock()
+	defer writerTypesMu.RUnlock()
+
+	f, ok := writerTypes[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown writer type %q", name)
+	}
+
+	return f, nil
+}
+
+// Decodifica i parametri di un writer, rifiutando quelli sconosciuti.
+func DecodeWriterParams(params json.RawMessage, v any) error {
+	if len(params) == 0 {
+		return nil
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(params))
+	dec.DisallowUnknownFields()
+
+	return dec.Decode(v)
+}
+
+// Writer di default in assenza di configurazione.
+func newDefaultWriter() logs.Writer {
+	return writers.NewStdoutWriter()
+}
+
+func init() {
+	RegisterWriterType("stdout", func(params json.RawMessage) (logs.Writer, error) {
+		err := DecodeWriterParams(params, &struct{}{})
+		if err != nil {
+			return nil, err
+		}
+
+		return writers.NewStdoutWriter(), nil
+	})
+
+	RegisterWriterType("file", func(params json.RawMessage) (logs.Writer, error) {
+		var p struct {
+			Filename string `json:"filename"`
+		}
+
+		err := DecodeWriterParams(params, &p)
+		if err != nil {
+			return nil, err
+		}
+
+		w, err := writers.NewFileWriter(p.Filename)
+		if err != nil {
+			return nil, err
+		}
+
+		return w, nil
+	})
+
+	RegisterWriterType("file_rotate", func(params json.RawMessage) (logs.Writer, error) {
+		var p struct {
+			Filename          string `json:"filename"`
+			RotateAfter       string `json:"rotate_after"`
+			DeleteNotCritical bool   `json:"delete_not_critical"`
+		}
+
+		err := DecodeWriterParams(params, &p)
+		if err != nil {
+			return nil, err
+		}
+
+		var rotateAfter time.Duration
+
+		if p.RotateAfter != "" {
+			rotateAfter, err = time.ParseDuration(p.RotateAfter)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		w, err := writers.NewFileRotateWriter(p.Filename, rotateAfter, p.DeleteNotCritical)
+		if err != nil {
+			return nil, err
+		}
+
+		return w, nil
+	})
+
+	RegisterWriterType("syslog", func(params json.RawMessage) (logs.Writer, error) {
+		var p struct {
+			Tag string `json:"tag"`
+		}
+
+		err := DecodeWriterParams(params, &p)
+		if err != nil {
+			return nil, err
+		}
+
+		return writers.NewSyslogWriter(p.Tag), nil
+	})
+
+	RegisterWriterType("tcp", func(params json.RawMessage) (logs.Writer, error) {
+		var p struct {
+			Address string `json:"address"`
+			Port    int    `json:"port"`
+			Debug   bool   `json:"debug"`
+		}
+
+		err := DecodeWriterParams(params, &p)
+		if err != nil {
+			return nil, err
+		}
+
+		w, err := writers.NewTCPWriter(p.Address, p.Port, p.Debug, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		return w, nil
+	})
+
+	RegisterWriterType("telegram", func(params json.RawMessage) (logs.Writer, error) {
+		var p struct {
+			APIKey    string `json:"api_key"`
+			ChannelID int    `json:"channel_id"`
+		}
+
+		err := DecodeWriterParams(params, &p)
+		if err != nil {
+			return nil, err
+		}
+
+		return writers.NewTelegramWriter(p.APIKey, p.ChannelID), nil
+	})
+}
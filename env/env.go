@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"runtime"
+	"strconv"
 	"strings"
 )
 
@@ -21,25 +22,184 @@ func GoroutineID() string {
 	return string(b)
 }
 
+// Frame is a single call of a stack trace.
+type Frame struct {
+	Function string `json:"function"`
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+}
+
+// CaptureCaller returns the calling frame, skipping the top most calls;
+// cheaper than CaptureStack since it resolves a single frame.
+// Returns nil if the frame is not available.
+func CaptureCaller(skip int) *Frame {
+	pcs := [1]uintptr{}
+	if runtime.Callers(skip+2, pcs[:]) == 0 {
+		return nil
+	}
+
+	frame, _ := runtime.CallersFrames(pcs[:]).Next()
+
+	return &Frame{
+		Function: frame.Function,
+		File:     frame.File,
+		Line:     frame.Line,
+	}
+}
+
+// Short returns the frame location as "dir/file.go:line",
+// keeping only the last directory of the file path.
+func (f *Frame) Short() string {
+	return string(f.AppendShort(nil))
+}
+
+// AppendShort appends the frame location as "dir/file.go:line" to buf.
+func (f *Frame) AppendShort(buf []byte) []byte {
+	file := f.File
+
+	if i := strings.LastIndexByte(file, '/'); i >= 0 {
+		if j := strings.LastIndexByte(file[:i], '/'); j >= 0 {
+			file = file[j+1:]
+		}
+	}
+
+	buf = append(buf, file...)
+	buf = append(buf, ':')
+
+	return strconv.AppendInt(buf, int64(f.Line), 10)
+}
+
+// Stack is the stack trace of a goroutine.
+type Stack struct {
+	GoroutineID string  `json:"goroutine"`
+	Frames      []Frame `json:"frames"`
+}
+
 // StackTrace returns the stack trace, skipping the top most calls.
 func StackTrace(skip int) string {
+	return CaptureStack(skip + 1).String()
+}
+
+// CaptureStack returns the structured stack trace of the current goroutine,
+// skipping the top most calls.
+func CaptureStack(skip int) *Stack {
 	const maxStackLength = 50
 	stackBuf := make([]uintptr, maxStackLength)
 	length := runtime.Callers(skip+2, stackBuf[:])
-	stack := stackBuf[:length]
 
-	trace := "STACKTRACE: goroutine #" + GoroutineID()
+	s := StackFromPCs(stackBuf[:length])
+	s.GoroutineID = GoroutineID()
+
+	return s
+}
 
-	frames := runtime.CallersFrames(stack)
+// StackFromPCs returns the structured stack trace of the program counters
+// returned by runtime.Callers (e.g. collected by an error at creation time).
+// The goroutine ID is left empty.
+func StackFromPCs(pcs []uintptr) *Stack {
+	var s Stack
+
+	if len(pcs) == 0 {
+		return &s
+	}
+
+	frames := runtime.CallersFrames(pcs)
 	for {
 		frame, more := frames.Next()
 		if !strings.Contains(frame.File, "runtime/") {
-			trace = trace + fmt.Sprintf("\n%s\n\t%s:%d", frame.Function, frame.File, frame.Line)
+			s.Frames = append(s.Frames, Frame{
+				Function: frame.Function,
+				File:     frame.File,
+				Line:     frame.Line,
+			})
 		}
 		if !more {
 			break
 		}
 	}
+
+	return &s
+}
+
+// ParseStack parses the first goroutine trace of a panic output, in the form:
+//
+//	goroutine 6 [running]:
+//	main.main.func1()
+//		/path/main.go:30 +0x1d
+//
+// Returns nil if no goroutine trace is found.
+func ParseStack(trace string) *Stack {
+	lines := strings.Split(strings.TrimSpace(trace), "\n")
+
+	if len(lines) == 0 || !strings.HasPrefix(lines[0], "goroutine ") {
+		return nil
+	}
+
+	var s Stack
+
+	if fields := strings.Fields(lines[0]); len(fields) > 1 {
+		s.GoroutineID = fields[1]
+	}
+
+	for i := 1; i+1 < len(lines); i += 2 {
+		if lines[i] == "" {
+			// Next goroutine.
+			break
+		}
+
+		f := Frame{
+			Function: strings.TrimSpace(lines[i]),
+			File:     strings.TrimSpace(lines[i+1]),
+		}
+
+		// Trim the " +0x1d" offset.
+		if n := strings.LastIndex(f.File, " +0x"); n >= 0 {
+			f.File = f.File[:n]
+		}
+
+		if n := strings.LastIndex(f.File, ":"); n >= 0 {
+			f.Line, _ = strconv.Atoi(f.File[n+1:])
+			f.File = f.File[:n]
+		}
+
+		s.Frames = append(s.Frames, f)
+	}
+
+	return &s
+}
+
+// String renders the stack trace:
+//
+//	STACKTRACE: goroutine #6
+//	main.main.func1
+//		/path/main.go:30
+func (s *Stack) String() string {
+	return s.Crop(0)
+}
+
+// Crop renders the stack trace as String() does, keeping only the whole frames
+// that fit in maxLength bytes (0 = no limit).
+// Returns an empty string if not even the header fits.
+func (s *Stack) Crop(maxLength int) string {
+	trace := "STACKTRACE:"
+	if s.GoroutineID != "" {
+		trace += " goroutine #" + s.GoroutineID
+	}
+
+	if maxLength > 0 && len(trace) > maxLength {
+		return ""
+	}
+
+	for _, f := range s.Frames {
+		line := fmt.Sprintf("\n%s\n\t%s:%d", f.Function, f.File, f.Line)
+
+		if maxLength > 0 && len(trace)+len(line) > maxLength {
+			break
+		}
+
+		trace += line
+	}
+
 	return trace
 }
 
@@ -1,5 +1,5 @@
 module github.com/modulo-srl/sparalog
 
-go 1.19
+go 1.21
 
 require github.com/mitchellh/panicwrap v1.0.0
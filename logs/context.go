@@ -0,0 +1,199 @@
+package logs
+
+// Integrazione con context.Context.
+
+import (
+	"context"
+	"sync"
+)
+
+type loggerContextKey struct{}
+type payloadContextKey struct{}
+
+// Chiavi di contesto registrate, i cui valori vengono riportati nel payload.
+var (
+	contextKeysMu sync.RWMutex
+	contextKeys   []contextKey
+)
+
+type contextKey struct {
+	name string
+	key  any
+}
+
+// Ritorna un contesto derivato da ctx che trasporta il logger.
+func NewContext(ctx context.Context, l *Logger) context.Context {
+	return context.WithValue(ctx, loggerContextKey{}, l)
+}
+
+// Ritorna il logger trasportato dal contesto, o il logger di default se assente.
+func FromContext(ctx context.Context) *Logger {
+	if ctx != nil {
+		if l, ok := ctx.Value(loggerContextKey{}).(*Logger); ok {
+			return l
+		}
+	}
+
+	return defaultLogger()
+}
+
+// Ritorna un contesto derivato da ctx che trasporta un valore di payload,
+// aggiunto agli item loggati con le varianti *Context().
+// Il payload del contesto padre non viene modificato.
+func ContextWithPayload(ctx context.Context, key string, value any) context.Context {
+	parent, _ := ctx.Value(payloadContextKey{}).(map[string]any)
+
+	payload := make(map[string]any, len(parent)+1)
+	for k, v := range parent {
+		payload[k] = v
+	}
+	payload[key] = value
+
+	return context.WithValue(ctx, payloadContextKey{}, payload)
+}
+
+// Registra una chiave di contesto: se presente nel contesto passato alle varianti *Context(),
+// il suo valore viene aggiunto al payload degli item con il nome specificato.
+// Utile per valori già trasportati dal contesto da altri package (request ID, user ID, trace ID...).
+func RegisterContextKey(name string, key any) {
+	contextKeysMu.Lock()
+	defer contextKeysMu.Unlock()
+
+	contextKeys = append(contextKeys, contextKey{
+		name: name,
+		key:  key,
+	})
+}
+
+// Aggiunge al payload dell'item i valori estratti dal contesto.
+func (i *Item) SetContextPayload(ctx context.Context) {
+	i.Payload = mergeContextPayload(ctx, i.Payload)
+}
+
+// Ritorna una copia del payload arricchita dai valori estratti dal contesto:
+// prima le chiavi registrate, poi il payload trasportato dal contesto.
+// Se il contesto non trasporta valori ritorna il payload originale.
+func mergeContextPayload(ctx context.Context, payload map[string]any) map[string]any {
+	var merged map[string]any
+
+	set := func(k string, v any) {
+		if merged == nil {
+			merged = make(map[string]any, len(payload)+1)
+			for k, v := range payload {
+				merged[k] = v
+			}
+		}
+		merged[k] = v
+	}
+
+	contextKeysMu.RLock()
+	for _, ck := range contextKeys {
+		if v := ctx.Value(ck.key); v != nil {
+			set(ck.name, v)
+		}
+	}
+	contextKeysMu.RUnlock()
+
+	if p, ok := ctx.Value(payloadContextKey{}).(map[string]any); ok {
+		for k, v := range p {
+			set(k, v)
+		}
+	}
+
+	if merged == nil {
+		return payload
+	}
+
+	return merged
+}
+
+// Logga a livello fatale effetuando anche os.Exit(FatalExitCode), con il payload del contesto.
+func (l *Logger) FatalContext(ctx context.Context, args ...any) {
+	l.logDepth(ctx, FatalLevel, 1, args...)
+}
+
+// Logga a livello critico, con il payload del contesto.
+func (l *Logger) CriticalContext(ctx context.Context, args ...any) {
+	l.logDepth(ctx, CriticalLevel, 1, args...)
+}
+
+// Logga a livello errore, con il payload del contesto.
+func (l *Logger) ErrorContext(ctx context.Context, args ...any) {
+	l.logDepth(ctx, ErrorLevel, 1, args...)
+}
+
+// Logga a livello warning, con il payload del contesto.
+func (l *Logger) WarningContext(ctx context.Context, args ...any) {
+	l.logDepth(ctx, WarningLevel, 1, args...)
+}
+
+// Logga a livello notice, con il payload del contesto.
+func (l *Logger) NoticeContext(ctx context.Context, args ...any) {
+	l.logDepth(ctx, NoticeLevel, 1, args...)
+}
+
+// Logga a livello info, con il payload del contesto.
+func (l *Logger) InfoContext(ctx context.Context, args ...any) {
+	l.logDepth(ctx, InfoLevel, 1, args...)
+}
+
+// Logga a livello debug, con il payload del contesto.
+func (l *Logger) DebugContext(ctx context.Context, args ...any) {
+	l.logDepth(ctx, DebugLevel, 1, args...)
+}
+
+// Logga a livello trace, con il payload del contesto.
+func (l *Logger) TraceContext(ctx context.Context, args ...any) {
+	l.logDepth(ctx, TraceLevel, 1, args...)
+}
+
+// Logga a un livello qualsiasi, con il payload del contesto.
+func (l *Logger) LogContext(ctx context.Context, level Level, args ...any) {
+	l.logDepth(ctx, level, 1, args...)
+}
+
+// Logga a livello fatale effetuando anche os.Exit(FatalExitCode),
+// usando il logger trasportato dal contesto e il relativo payload.
+func FatalContext(ctx context.Context, args ...any) {
+	FromContext(ctx).logDepth(ctx, FatalLevel, 1, args...)
+}
+
+// Logga a livello critico, usando il logger trasportato dal contesto e il relativo payload.
+func CriticalContext(ctx context.Context, args ...any) {
+	FromContext(ctx).logDepth(ctx, CriticalLevel, 1, args...)
+}
+
+// Logga a livello errore, usando il logger trasportato dal contesto e il relativo payload.
+func ErrorContext(ctx context.Context, args ...any) {
+	FromContext(ctx).logDepth(ctx, ErrorLevel, 1, args...)
+}
+
+// Logga a livello warning, usando il logger trasportato dal contesto e il relativo payload.
+func WarningContext(ctx context.Context, args ...any) {
+	FromContext(ctx).logDepth(ctx, WarningLevel, 1, args...)
+}
+
+// Logga a livello notice, usando il logger trasportato dal contesto e il relativo payload.
+func NoticeContext(ctx context.Context, args ...any) {
+	FromContext(ctx).logDepth(ctx, NoticeLevel, 1, args...)
+}
+
+// Logga a livello info, usando il logger trasportato dal contesto e il relativo payload.
+func InfoContext(ctx context.Context, args ...any) {
+	FromContext(ctx).logDepth(ctx, InfoLevel, 1, args...)
+}
+
+// Logga a livello debug, usando il logger trasportato dal contesto e il relativo payload.
+func DebugContext(ctx context.Context, args ...any) {
+	FromContext(ctx).logDepth(ctx, DebugLevel, 1, args...)
+}
+
+// Logga a livello trace, usando il logger trasportato dal contesto e il relativo payload.
+func TraceContext(ctx context.Context, args ...any) {
+	FromContext(ctx).logDepth(ctx, TraceLevel, 1, args...)
+}
+
+// Logga a un livello qualsiasi, usando il logger trasportato dal contesto e il relativo payload.
+func LogContext(ctx context.Context, level Level, args ...any) {
+	FromContext(ctx).logDepth(ctx, level, 1, args...)
+}
@@ -1,39 +1,27 @@
 package logs
 
-// Funzioni interne di gestione logger di default.
-
-// Logger globale di default.
-var defaultLogger *Logger
-
-// Dispatcher globale.
-var globalDispatcher *dispatcher
-
-// Per quali livelli lo stacktrace è abilitato.
-var levelsStackTrace [LevelsCount]bool
+// Funzioni interne di gestione del sistema di default.
 
 // Invocata da sparalog.init()
-// Inizializza la libreria allocando il logger di default
+// Inizializza la libreria allocando il sistema di default
 // e associando un writer di default (di tipo Stdout).
+// Il sistema precedente viene abbandonato, senza essere stoppato.
+// Formato dei timestamp e orologio, globali al processo, non vengono reimpostati
+// (vedi SetTimeFormat() e SetClock()).
 func InitDefaultLogger(defaultWriter Writer) {
-	defaultLogger = &Logger{
-		// Skippa una chiamata in più dato che il logger di default
-		// viene invocato da funzioni wrapper.
-		stackCallsToSkip: 1,
-	}
+	s := NewSystem(defaultWriter)
 
-	// Inizializza il dispatcher.
-	globalDispatcher = newDispatcher(defaultWriter)
+	// I logger del sistema di default fanno riferimento al sistema di default corrente,
+	// anche se allocati prima di una reinizializzazione (es. variabili di package).
+	s.logger.system = nil
 
-	// Abilita lo stacktrace per i soli livelli fatal, error.
-	EnableLevelsStackTrace([]Level{FatalLevel, ErrorLevel})
-
-	globalDispatcher.Mute(DebugLevel, true)
+	defaultSystem.Store(s)
 }
 
 // Invocata da sparalog.Start()
-// Avvia il logger di default.
+// Avvia il sistema di default.
 func StartDefaultLogger() {
-	globalDispatcher.Start()
+	DefaultSystem().Start()
 }
 
 // Invocata da sparalog.Stop()
@@ -46,10 +34,5 @@ func StopDefaultLogger() {
 		Fatal(err)
 	}
 
-	if globalDispatcher == nil {
-		return
-	}
-
-	globalDispatcher.Stop()
-	globalDispatcher = nil
+	DefaultSystem().Stop()
 }
@@ -3,25 +3,71 @@ package logs
 // Dispatcher del logger.
 
 import (
-	"os"
+	"errors"
+	"fmt"
 	"runtime"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
 type dispatcher struct {
-	levelWriters [LevelsCount]levelWriters
+	// Tabella di routing corrente: non viene mai modificata in place,
+	// ogni riconfigurazione ne alloca una copia e la sostituisce atomicamente (copy on write).
+	routes atomic.Pointer[routingTable]
 
 	writersFeedback   chan *Item
 	writersFeedbackWG sync.WaitGroup
 
-	mu     sync.RWMutex
-	muted  [LevelsCount]bool
-	closed bool
+	// Protegge la chiusura del canale di feedback dagli invii di Flush().
+	feedbackMu     sync.RWMutex
+	feedbackClosed bool
+
+	// Marcatori inviati sul canale di feedback da Flush(), con il relativo canale di notifica,
+	// e numero di item di feedback elaborati.
+	feedbackMarkers sync.Map
+	feedbackCount   atomic.Int64
+
+	// Soppressione duplicati e rate limiting.
+	throttle *throttle
+
+	// Serializza le riconfigurazioni e l'avvio/arresto dei writer.
+	mu             sync.Mutex
+	started        bool
+	startedWriters map[Writer]bool
+
+	closed atomic.Bool
+
+	// Impedisce gli invii ai writer, impostato da Stop() dopo aver riportato i riepiloghi pendenti.
+	writesClosed atomic.Bool
+
+	// Hook, relativo timeout e funzione di terminazione per le loggate fatali.
+	fatalMu           sync.Mutex
+	fatalHooks        []FatalHook
+	fatalHooksTimeout time.Duration
+	exitF             ExitFunc
+
+	// Solo la prima loggata fatale viene gestita, le successive ne attendono il termine.
+	fatalOnce sync.Once
+	fatalDone chan struct{}
+}
+
+type routingTable struct {
+	// Indicizzati per livello. I livelli registrati dopo l'allocazione della tabella
+	// vi vengono aggiunti alla prima riconfigurazione, ereditando l'instradamento di fallback.
+	levelWriters []levelWriters
+	muted        []bool
+
+	// Instradamento dei livelli non ancora presenti nella tabella:
+	// riceve le modifiche applicate a tutti i livelli.
+	fallback levelWriters
+
+	// Invii ai writer in corso sulla tabella, vedi acquireRoutes().
+	inflight atomic.Int64
 }
 
 type levelWriters struct {
-	writers       map[Writer]bool
+	writers       []Writer
 	defaultWriter Writer
 }
 
@@ -29,8 +75,17 @@ type levelWriters struct {
 func newDispatcher(defaultWriter Writer) *dispatcher {
 	d := dispatcher{
 		writersFeedback: make(chan *Item, 64),
+		startedWriters:  make(map[Writer]bool),
+		fatalDone:       make(chan struct{}),
+
+		fatalHooksTimeout: DefaultFatalHooksTimeout,
 	}
 
+	d.throttle = newThrottle(d.writeSummary, func(level Level) bool {
+		return d.routes.Load().isMuted(level)
+	})
+
+	d.routes.Store(&routingTable{})
 	d.ResetWriters(defaultWriter)
 
 	d.startFeedbackWatcher()
@@ -43,103 +98,223 @@ func newDispatcher(defaultWriter Writer) *dispatcher {
 
 // Disassocia tutti i writer e reimposta un writer di default
 // (il writer di default riceve le eventuali loggate di errore o di feedback da parte degli altri writer).
-// NON thread safe.
-func (d *dispatcher) ResetWriters(defaultW Writer) {
-	for i := 0; i < int(LevelsCount); i++ {
-		d.ResetLevelWriters(Level(i), defaultW)
-	}
+func (d *dispatcher) ResetWriters(defaultW Writer) error {
+	return d.update(func(t *routingTable) {
+		for _, lw := range t.all() {
+			lw.reset(defaultW)
+		}
+	})
 }
 
 // Disassocia tutti i writer per un certo livello e ne reimposta un writer di default
 // (il writer di default riceve le eventuali loggate di errore o di feedback da parte degli altri writer).
-// NON thread safe.
-func (d *dispatcher) ResetLevelWriters(level Level, defaultW Writer) {
-	l := levelWriters{
-		writers:       make(map[Writer]bool),
-		defaultWriter: defaultW,
-	}
-
-	if defaultW != nil {
-		l.writers[defaultW] = true
-	}
-
-	d.levelWriters[level] = l
+func (d *dispatcher) ResetLevelWriters(level Level, defaultW Writer) error {
+	return d.update(func(t *routingTable) {
+		t.levelWriters[level].reset(defaultW)
+	})
 }
 
 // Disassocia tutti i writer per un set di livelli e ne reimposta un writer di default
 // (il writer di default riceve le eventuali loggate di errore o di feedback da parte degli altri writer).
-// NON thread safe.
-func (d *dispatcher) ResetLevelsWriters(levels []Level, defaultW Writer) {
-	for _, level := range levels {
-		d.ResetLevelWriters(level, defaultW)
-	}
+func (d *dispatcher) ResetLevelsWriters(levels []Level, defaultW Writer) error {
+	return d.update(func(t *routingTable) {
+		for _, level := range levels {
+			t.levelWriters[level].reset(defaultW)
+		}
+	})
 }
 
 // Associa un writer a tutti i livelli.
-// NON thread safe.
-func (d *dispatcher) AddWriter(w Writer) {
-	for level := 0; level < int(LevelsCount); level++ {
-		d.levelWriters[level].writers[w] = true
-	}
-
-	w.SetFeedbackChan(d.writersFeedback)
+func (d *dispatcher) AddWriter(w Writer) error {
+	return d.update(func(t *routingTable) {
+		for _, lw := range t.all() {
+			lw.add(w)
+		}
+	})
 }
 
 // Associa un writer a uno specifico livello.
-// NON thread safe.
-func (d *dispatcher) AddLevelWriter(level Level, w Writer) {
-	d.levelWriters[level].writers[w] = true
-
-	w.SetFeedbackChan(d.writersFeedback)
+func (d *dispatcher) AddLevelWriter(level Level, w Writer) error {
+	return d.update(func(t *routingTable) {
+		t.levelWriters[level].add(w)
+	})
 }
 
 // Associa un writer a un set di livelli.
-// NON thread safe.
-func (d *dispatcher) AddLevelsWriter(levels []Level, w Writer) {
-	for _, level := range levels {
-		d.levelWriters[level].writers[w] = true
-	}
+func (d *dispatcher) AddLevelsWriter(levels []Level, w Writer) error {
+	return d.update(func(t *routingTable) {
+		for _, level := range levels {
+			t.levelWriters[level].add(w)
+		}
+	})
+}
 
-	w.SetFeedbackChan(d.writersFeedback)
+// Sostituisce atomicamente tutte le associazioni dei writer:
+// gli item vengono inviati o alla vecchia o alla nuova configurazione, mai a una intermedia.
+// I writer già associati e presenti anche nella nuova configurazione non vengono riavviati.
+func (d *dispatcher) ReplaceWriters(defaultW Writer, routes []WriterRoute) error {
+	return d.update(func(t *routingTable) {
+		for _, lw := range t.all() {
+			lw.reset(defaultW)
+		}
+
+		for _, r := range routes {
+			if r.Levels == nil {
+				for _, lw := range t.all() {
+					lw.add(r.Writer)
+				}
+				continue
+			}
+
+			for _, level := range r.Levels {
+				t.levelWriters[level].add(r.Writer)
+			}
+		}
+	})
 }
 
-// Muta o smuta un livello.
-func (d *dispatcher) Mute(level Level, state bool) {
+// Disassocia un writer da tutti i livelli, stoppandolo.
+func (d *dispatcher) RemoveWriter(id string) error {
+	return d.remove(id, func(t *routingTable, w Writer) {
+		for _, lw := range t.all() {
+			lw.remove(w)
+		}
+	})
+}
+
+// Disassocia un writer da uno specifico livello,
+// stoppandolo se non più associato ad alcun livello.
+func (d *dispatcher) RemoveLevelWriter(level Level, id string) error {
+	return d.remove(id, func(t *routingTable, w Writer) {
+		t.levelWriters[level].remove(w)
+	})
+}
+
+// Ritorna le informazioni su tutti i writer associati ad almeno un livello,
+// nell'ordine in cui compaiono scorrendo i livelli.
+func (d *dispatcher) Writers() []WriterInfo {
 	d.mu.Lock()
 	defer d.mu.Unlock()
 
-	d.muted[level] = state
+	var infos []WriterInfo
+	indexes := make(map[Writer]int)
+
+	t := d.routes.Load()
+
+	for i := 0; i < levelsCount(); i++ {
+		level := Level(i)
+		lw := t.get(level)
+
+		for _, w := range lw.writers {
+			i, ok := indexes[w]
+			if !ok {
+				i = len(infos)
+				indexes[w] = i
+
+				infos = append(infos, WriterInfo{
+					ID:      w.ID(),
+					Name:    w.Name(),
+					Started: d.startedWriters[w],
+				})
+			}
+
+			infos[i].Levels = append(infos[i].Levels, level)
+
+			if lw.defaultWriter == w {
+				infos[i].DefaultLevels = append(infos[i].DefaultLevels, level)
+			}
+		}
+	}
+
+	return infos
+}
+
+// Muta o smuta un livello.
+func (d *dispatcher) Mute(level Level, state bool) {
+	d.update(func(t *routingTable) {
+		t.muted[level] = state
+	})
 }
 
-// Invia un item a tutti i writer del livello.
+// Invia un item a tutti i writer del livello,
+// salvo che venga soppresso come duplicato o per rate limiting.
 func (d *dispatcher) Dispatch(item *Item) {
-	for w := range d.levelWriters[item.Level].writers {
-		w.Write(item)
+	if d.throttle.Allow(item) {
+		d.write(item)
 	}
 
 	if item.Level == FatalLevel {
-		d.Stop()
-		os.Exit(FatalExitCode)
+		// Mai rilasciato: gli hook oltre il timeout possono ancora utilizzarlo.
+		item.Retain()
+		d.fatal(item)
+	}
+}
+
+// Invia un item di riepilogo della soppressione duplicati o del rate limiting
+// ai writer del livello, salvo che questo sia stato mutato (vedi throttle.emit).
+// A differenza di CanDispatch() non verifica l'arresto del dispatcher,
+// dal momento che i riepiloghi pendenti vengono riportati da Stop().
+func (d *dispatcher) writeSummary(item *Item, ignoreMute bool) {
+	if !ignoreMute && d.routes.Load().isMuted(item.Level) {
+		return
+	}
+
+	d.write(item)
+}
+
+// Invia un item a tutti i writer del livello, salvo che il dispatcher sia stato arrestato.
+func (d *dispatcher) write(item *Item) {
+	t := d.acquireRoutes()
+	if t == nil {
+		return
+	}
+	defer t.inflight.Add(-1)
+
+	for _, w := range t.get(item.Level).writers {
+		if _, ok := w.(PoolSafeWriter); !ok {
+			// Il writer potrebbe conservare l'item: mai rilasciato, viene escluso dal riciclo.
+			item.Retain()
+		}
+
+		w.Write(item)
+	}
+}
+
+// Ritorna la tabella di routing corrente registrandovi un invio in corso,
+// da terminare con t.inflight.Add(-1); ritorna nil se il dispatcher è stato arrestato.
+// Una volta sostituita la tabella, o arrestato il dispatcher, è sufficiente attendere
+// il termine degli invii in corso (vedi waitInflight()) perché i writer non ricevano altri item.
+func (d *dispatcher) acquireRoutes() *routingTable {
+	for {
+		t := d.routes.Load()
+		t.inflight.Add(1)
+
+		if d.writesClosed.Load() {
+			t.inflight.Add(-1)
+			return nil
+		}
+
+		if d.routes.Load() == t {
+			return t
+		}
+
+		// Tabella sostituita nel frattempo.
+		t.inflight.Add(-1)
 	}
 }
 
 // Avvia tutti i writer.
+// I writer associati successivamente vengono avviati automaticamente.
 func (d *dispatcher) Start() error {
-	openedWw := make(map[Writer]bool)
-
-	for _, lw := range d.levelWriters {
-		for w := range lw.writers {
-			// Assicura una sola chiamata a writer.Open()
-			if _, ok := openedWw[w]; ok {
-				continue
-			}
+	d.mu.Lock()
+	defer d.mu.Unlock()
 
-			err := w.Start()
-			if err != nil {
-				return err
-			}
+	d.started = true
 
-			openedWw[w] = true
+	for w := range d.routes.Load().allWriters() {
+		err := d.startWriter(w)
+		if err != nil {
+			return err
 		}
 	}
 
@@ -148,63 +323,255 @@ func (d *dispatcher) Start() error {
 
 // Stoppa tutti i writer e il canale di feedback.
 func (d *dispatcher) Stop() {
-	d.mu.Lock()
-	if d.closed {
-		d.mu.Unlock()
+	if !d.closed.CompareAndSwap(false, true) {
 		return
 	}
-	d.closed = true
+
+	// Riporta i conteggi di duplicati e item scartati ancora pendenti.
+	d.throttle.Flush()
+
+	d.mu.Lock()
+
+	// Attende gli invii in corso: i writer sincroni possono ancora feedbackare.
+	d.writesClosed.Store(true)
+	d.routes.Load().waitInflight(stopTimeout)
+
+	// Stoppa i writer attendendo che terminino le proprie code:
+	// gli eventuali errori vengono ancora feedbackati ai writer di default.
+	d.started = false
+	for w := range d.startedWriters {
+		d.stopWriter(w)
+	}
+
+	// Distacca i writer dal canale di feedback (attendendo gli invii in corso),
+	// per gli eventuali feedback generati da goroutine dei writer ancora attive.
+	for w := range d.routes.Load().allWriters() {
+		w.SetFeedbackChan(nil)
+	}
+
 	d.mu.Unlock()
 
 	// Chiude e vuota il canale di feedback.
+	d.feedbackMu.Lock()
+	d.feedbackClosed = true
 	close(d.writersFeedback)
-	waitTimeout(&d.writersFeedbackWG, time.Second*time.Duration(3))
+	d.feedbackMu.Unlock()
 
-	// Stoppa i writer attendendo che terminino le proprie code.
-	closedWw := make(map[Writer]bool)
+	waitTimeout(&d.writersFeedbackWG, stopTimeout)
+}
 
-	for _, lw := range d.levelWriters {
-		for w := range lw.writers {
-			// Assicura una singola chiamata a writer.Stop()
-			if _, ok := closedWw[w]; ok {
-				continue
+// Tempo massimo di attesa degli invii in corso e del canale di feedback all'arresto.
+const stopTimeout = 3 * time.Second
+
+// Attende che i writer asincroni abbiano consegnato gli item accodati
+// e che le eventuali loggate di feedback siano state inviate ai writer di default,
+// al più per timeout.
+func (d *dispatcher) Flush(timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+
+	for {
+		count := d.feedbackCount.Load()
+
+		for w := range d.routes.Load().allWriters() {
+			if f, ok := w.(Flusher); ok && !f.Flush(time.Until(deadline)) {
+				return fmt.Errorf("flush timeout: writer %q", w.ID())
 			}
+		}
+
+		if !d.flushFeedback(time.Until(deadline)) {
+			return errors.New("flush timeout: feedback")
+		}
 
-			w.Stop()
-			closedWw[w] = true
+		// Nessuna nuova loggata di feedback inviata ai writer.
+		if d.feedbackCount.Load() == count {
+			return nil
 		}
 	}
 }
 
+// Attende che le loggate di feedback già inviate siano state elaborate,
+// inviando un marcatore sul canale e attendendone la ricezione.
+func (d *dispatcher) flushFeedback(timeout time.Duration) bool {
+	marker := &Item{}
+	done := make(chan struct{})
+	d.feedbackMarkers.Store(marker, done)
+	defer d.feedbackMarkers.Delete(marker)
+
+	t := time.NewTimer(timeout)
+	defer t.Stop()
+
+	d.feedbackMu.RLock()
+
+	// Il canale viene chiuso in fase di arresto: non c'è più nulla da attendere.
+	if d.feedbackClosed {
+		d.feedbackMu.RUnlock()
+		return true
+	}
+
+	select {
+	case d.writersFeedback <- marker:
+	case <-t.C:
+		d.feedbackMu.RUnlock()
+		return false
+	}
+
+	d.feedbackMu.RUnlock()
+
+	select {
+	case <-done:
+		return true
+	case <-t.C:
+		return false
+	}
+}
+
 // Ritorna true se il livello ha almeno un writer che non sia mutato.
 func (d *dispatcher) CanDispatch(level Level) bool {
-	if len(d.levelWriters[level].writers) == 0 {
+	return d.canDispatch(level, false)
+}
+
+// Ritorna true se il livello ha almeno un writer, anche se mutato.
+func (d *dispatcher) CanDispatchMuted(level Level) bool {
+	return d.canDispatch(level, true)
+}
+
+func (d *dispatcher) canDispatch(level Level, ignoreMute bool) bool {
+	t := d.routes.Load()
+
+	if len(t.get(level).writers) == 0 {
 		return false
 	}
 
-	d.mu.RLock()
-	defer d.mu.RUnlock()
-
-	if d.closed || d.muted[level] {
+	if d.closed.Load() || (t.isMuted(level) && !ignoreMute) {
 		return false
 	}
 
 	return true
 }
 
+// Applica una modifica a una copia della tabella di routing e la rende effettiva atomicamente.
+// I writer aggiunti vengono avviati prima di ricevere item (se il dispatcher è già avviato),
+// quelli rimossi vengono stoppati dopo il termine delle Dispatch in corso sulla tabella precedente.
+// Se l'avvio di un writer fallisce la modifica viene annullata.
+func (d *dispatcher) update(f func(t *routingTable)) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	old := d.routes.Load()
+	t := old.clone()
+	f(t)
+
+	oldWriters := old.allWriters()
+	newWriters := t.allWriters()
+
+	var startedWw []Writer
+
+	for w := range newWriters {
+		if oldWriters[w] {
+			continue
+		}
+
+		w.SetFeedbackChan(d.writersFeedback)
+
+		if d.started && !d.startedWriters[w] {
+			err := d.startWriter(w)
+			if err != nil {
+				// Annulla gli avvii già effettuati.
+				for _, sw := range startedWw {
+					d.stopWriter(sw)
+				}
+				return err
+			}
+
+			startedWw = append(startedWw, w)
+		}
+	}
+
+	d.routes.Store(t)
+
+	removed := false
+	for w := range oldWriters {
+		if !newWriters[w] {
+			removed = true
+			break
+		}
+	}
+
+	if removed {
+		// I writer rimossi vengono stoppati solo dopo il termine degli invii
+		// in corso sulla tabella precedente.
+		old.waitInflight(stopTimeout)
+
+		for w := range oldWriters {
+			if !newWriters[w] {
+				d.stopWriter(w)
+			}
+		}
+	}
+
+	return nil
+}
+
+// Cerca il writer per ID e applica la rimozione f alla tabella di routing.
+func (d *dispatcher) remove(id string, f func(t *routingTable, w Writer)) error {
+	w := d.routes.Load().findWriter(id)
+	if w == nil {
+		return fmt.Errorf("writer %q not found", id)
+	}
+
+	return d.update(func(t *routingTable) {
+		f(t, w)
+	})
+}
+
+// Avvia un writer se non già avviato.
+// Va invocata con d.mu acquisito.
+func (d *dispatcher) startWriter(w Writer) error {
+	if d.startedWriters[w] {
+		return nil
+	}
+
+	err := w.Start()
+	if err != nil {
+		return err
+	}
+
+	d.startedWriters[w] = true
+
+	return nil
+}
+
+// Stoppa un writer se avviato.
+// Va invocata con d.mu acquisito.
+func (d *dispatcher) stopWriter(w Writer) {
+	if !d.startedWriters[w] {
+		return
+	}
+
+	w.Stop()
+	delete(d.startedWriters, w)
+}
+
 func (d *dispatcher) startFeedbackWatcher() {
 	d.writersFeedbackWG.Add(1)
 
 	go func() {
 		for item := range d.writersFeedback {
-			w := d.levelWriters[item.Level].defaultWriter
+			if done, ok := d.feedbackMarkers.LoadAndDelete(item); ok {
+				close(done.(chan struct{}))
+				continue
+			}
+
+			d.feedbackCount.Add(1)
+
+			w := d.routes.Load().get(item.Level).defaultWriter
 			if w != nil {
 				w.Write(item)
 			}
 
 			if item.Level == FatalLevel {
-				d.Stop()
-				os.Exit(FatalExitCode)
+				// Stop() attende il termine di questa goroutine.
+				go d.fatal(item)
 			}
 		}
 
@@ -212,6 +579,127 @@ func (d *dispatcher) startFeedbackWatcher() {
 	}()
 }
 
+// Attende il termine degli invii in corso sulla tabella, al più per timeout
+// (un writer che logga durante il proprio Write() ne ritarda l'arresto).
+func (t *routingTable) waitInflight(timeout time.Duration) {
+	deadline := time.Now().Add(timeout)
+
+	for t.inflight.Load() > 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// Ritorna una copia della tabella, modificabile senza impatti sulla tabella originale,
+// estesa a tutti i livelli registrati.
+func (t *routingTable) clone() *routingTable {
+	n := levelsCount()
+
+	c := routingTable{
+		levelWriters: make([]levelWriters, n),
+		muted:        make([]bool, n),
+		fallback:     t.fallback.clone(),
+	}
+
+	for i := range c.levelWriters {
+		c.levelWriters[i] = t.get(Level(i)).clone()
+	}
+
+	copy(c.muted, t.muted)
+
+	return &c
+}
+
+// Ritorna l'instradamento di un livello.
+func (t *routingTable) get(level Level) *levelWriters {
+	if level >= 0 && int(level) < len(t.levelWriters) {
+		return &t.levelWriters[level]
+	}
+
+	return &t.fallback
+}
+
+func (t *routingTable) isMuted(level Level) bool {
+	return level >= 0 && int(level) < len(t.muted) && t.muted[level]
+}
+
+// Ritorna l'instradamento di tutti i livelli, incluso quello di fallback.
+func (t *routingTable) all() []*levelWriters {
+	all := make([]*levelWriters, 0, len(t.levelWriters)+1)
+
+	for i := range t.levelWriters {
+		all = append(all, &t.levelWriters[i])
+	}
+
+	return append(all, &t.fallback)
+}
+
+// Ritorna il writer con l'ID specificato, o nil se non associato ad alcun livello.
+func (t *routingTable) findWriter(id string) Writer {
+	for _, lw := range t.all() {
+		for _, w := range lw.writers {
+			if w.ID() == id {
+				return w
+			}
+		}
+	}
+
+	return nil
+}
+
+// Ritorna il set di tutti i writer associati ad almeno un livello.
+func (t *routingTable) allWriters() map[Writer]bool {
+	ww := make(map[Writer]bool)
+
+	for _, lw := range t.all() {
+		for _, w := range lw.writers {
+			ww[w] = true
+		}
+	}
+
+	return ww
+}
+
+func (lw levelWriters) clone() levelWriters {
+	lw.writers = append([]Writer(nil), lw.writers...)
+	return lw
+}
+
+// Disassocia tutti i writer e reimposta quello di default.
+func (lw *levelWriters) reset(defaultW Writer) {
+	*lw = levelWriters{
+		defaultWriter: defaultW,
+	}
+
+	if defaultW != nil {
+		lw.writers = []Writer{defaultW}
+	}
+}
+
+// Associa un writer, se non già associato.
+func (lw *levelWriters) add(w Writer) {
+	for _, ww := range lw.writers {
+		if ww == w {
+			return
+		}
+	}
+
+	lw.writers = append(lw.writers, w)
+}
+
+// Disassocia un writer; se era il writer di default ne rimane privo.
+func (lw *levelWriters) remove(w Writer) {
+	for i, ww := range lw.writers {
+		if ww == w {
+			lw.writers = append(lw.writers[:i], lw.writers[i+1:]...)
+			break
+		}
+	}
+
+	if lw.defaultWriter == w {
+		lw.defaultWriter = nil
+	}
+}
+
 // Wait for a WaitGroup with a timeout.
 // Returns false when timeouted.
 func waitTimeout(wg *sync.WaitGroup, timeout time.Duration) bool {
@@ -0,0 +1,194 @@
+package logs
+
+// Loggata strutturata degli errori.
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/modulo-srl/sparalog/env"
+)
+
+// Interfaccia opzionale degli errori che trasportano valori da riportare nel payload
+// (es. ID di entità, codici di errore).
+type LogFielder interface {
+	LogFields() map[string]any
+}
+
+// Profondità massima delle catene di errori esplorate.
+const maxErrorDepth = 32
+
+// Logga un errore a livello errore (vedi Item.SetError()).
+func (l *Logger) Err(err error) {
+	l.logErr(ErrorLevel, err, 2)
+}
+
+// Logga un errore al livello specificato (vedi Item.SetError()).
+func (l *Logger) ErrLevel(level Level, err error) {
+	l.logErr(level, err, 2)
+}
+
+// Logga un errore a livello errore (vedi Item.SetError()).
+func Err(err error) {
+	defaultLogger().logErr(ErrorLevel, err, 2)
+}
+
+// Logga un errore al livello specificato (vedi Item.SetError()).
+func ErrLevel(level Level, err error) {
+	defaultLogger().logErr(level, err, 2)
+}
+
+// - stackCallsToSkip: chiamate da escludere dallo stacktrace.
+func (l *Logger) logErr(level Level, err error, stackCallsToSkip int) {
+	d := l.getDispatcher()
+
+	if err == nil || !l.canDispatch(d, level) {
+		return
+	}
+
+	item := l.newSelfItem(level, stackCallsToSkip, err.Error())
+	item.SetError(err)
+
+	d.Dispatch(item)
+}
+
+// Arricchisce l'item con le informazioni strutturate dell'errore e della sua catena
+// (errors.Unwrap ed errors.Join):
+//   - payload "causes": le cause, ognuna con "message" e "type", annidate per gli errori multipli;
+//   - i valori degli errori che implementano LogFielder (quelli più esterni prevalgono);
+//   - lo stacktrace dell'errore più interno che ne trasporta uno,
+//     tramite un metodo Callers() []uintptr o StackTrace() (es. github.com/pkg/errors).
+func (i *Item) SetError(err error) {
+	if err == nil {
+		return
+	}
+
+	var (
+		fielders []LogFielder
+		stack    *env.Stack
+	)
+
+	visitErrors(err, 0, func(e error) {
+		if f, ok := e.(LogFielder); ok {
+			fielders = append(fielders, f)
+		}
+
+		if s := errorStack(e); s != nil {
+			stack = s
+		}
+	})
+
+	if causes := errorCauses(err, 0); len(causes) > 0 {
+		i.SetPayload("causes", causes)
+	}
+
+	for n := len(fielders) - 1; n >= 0; n-- {
+		for k, v := range fielders[n].LogFields() {
+			i.SetPayload(k, v)
+		}
+	}
+
+	if stack != nil {
+		i.Stack = stack
+	}
+}
+
+// Visita l'errore e la sua catena in profondità, dal più esterno al più interno.
+func visitErrors(err error, depth int, f func(error)) {
+	if err == nil || depth > maxErrorDepth {
+		return
+	}
+
+	f(err)
+
+	switch u := err.(type) {
+	case interface{ Unwrap() error }:
+		visitErrors(u.Unwrap(), depth+1, f)
+
+	case interface{ Unwrap() []error }:
+		for _, e := range u.Unwrap() {
+			visitErrors(e, depth+1, f)
+		}
+	}
+}
+
+// Ritorna le cause dell'errore: la catena di errori annidati è riportata come lista,
+// i rami degli errori multipli come "causes" della relativa voce.
+func errorCauses(err error, depth int) []any {
+	var causes []any
+
+	for ; depth < maxErrorDepth; depth++ {
+		switch u := err.(type) {
+		case interface{ Unwrap() error }:
+			err = u.Unwrap()
+			if err == nil {
+				return causes
+			}
+
+			// Le cause di un errore multiplo vengono annidate nella sua voce.
+			_, multi := err.(interface{ Unwrap() []error })
+
+			causes = append(causes, errorCause(err, depth, multi))
+			if multi {
+				return causes
+			}
+
+		case interface{ Unwrap() []error }:
+			for _, e := range u.Unwrap() {
+				if e != nil {
+					causes = append(causes, errorCause(e, depth, true))
+				}
+			}
+			return causes
+
+		default:
+			return causes
+		}
+	}
+
+	return causes
+}
+
+// Ritorna la voce di una causa, eventualmente con le sue cause annidate.
+func errorCause(err error, depth int, nested bool) map[string]any {
+	cause := map[string]any{
+		"message": err.Error(),
+		"type":    fmt.Sprintf("%T", err),
+	}
+
+	if nested {
+		if causes := errorCauses(err, depth+1); len(causes) > 0 {
+			cause["causes"] = causes
+		}
+	}
+
+	return cause
+}
+
+// Ritorna lo stacktrace trasportato dall'errore, o nil.
+func errorStack(err error) *env.Stack {
+	if e, ok := err.(interface{ Callers() []uintptr }); ok {
+		return env.StackFromPCs(e.Callers())
+	}
+
+	// Metodo StackTrace() che ritorna una slice di program counter di un tipo derivato da uintptr,
+	// come github.com/pkg/errors.StackTrace.
+	m := reflect.ValueOf(err).MethodByName("StackTrace")
+	if !m.IsValid() || m.Type().NumIn() != 0 || m.Type().NumOut() != 1 {
+		return nil
+	}
+
+	t := m.Type().Out(0)
+	if t.Kind() != reflect.Slice || t.Elem().Kind() != reflect.Uintptr {
+		return nil
+	}
+
+	v := m.Call(nil)[0]
+
+	pcs := make([]uintptr, v.Len())
+	for n := range pcs {
+		pcs[n] = uintptr(v.Index(n).Uint())
+	}
+
+	return env.StackFromPCs(pcs)
+}
@@ -0,0 +1,141 @@
+package logs
+
+// Gestione delle loggate fatali: hook, flush dei writer e terminazione del processo.
+
+import (
+	"os"
+	"time"
+)
+
+// Tempo massimo di default concesso agli hook OnFatal, vedi SetFatalHooksTimeout().
+const DefaultFatalHooksTimeout = 5 * time.Second
+
+// Imposta il tempo massimo concesso complessivamente agli hook OnFatal del logger di default,
+// scaduto il quale si procede comunque con l'arresto dei writer e la terminazione
+// (default DefaultFatalHooksTimeout).
+func SetFatalHooksTimeout(timeout time.Duration) {
+	DefaultSystem().SetFatalHooksTimeout(timeout)
+}
+
+// Hook invocato a ogni loggata fatale, con la loggata stessa.
+type FatalHook func(*Item)
+
+// Funzione di terminazione del processo, di default os.Exit.
+type ExitFunc func(code int)
+
+// Registra un hook invocato a ogni loggata fatale del logger di default,
+// prima dell'arresto dei writer (gli hook possono quindi ancora loggare).
+// Gli hook vengono invocati in ordine di registrazione, entro il timeout impostato con SetFatalHooksTimeout().
+func OnFatal(hook FatalHook) {
+	DefaultSystem().OnFatal(hook)
+}
+
+// Sostituisce la funzione di terminazione invocata dopo una loggata fatale
+// del logger di default (nil = os.Exit); utile nei test per intercettare i fatali.
+func SetExitFunc(f ExitFunc) {
+	DefaultSystem().SetExitFunc(f)
+}
+
+// Registra un hook invocato a ogni loggata fatale dei logger del sistema del logger.
+// Per i logger del sistema di default equivale a OnFatal(), e vale quindi per l'intero processo.
+func (l *Logger) OnFatal(hook FatalHook) {
+	l.getDispatcher().OnFatal(hook)
+}
+
+// Sostituisce la funzione di terminazione del sistema del logger (nil = os.Exit).
+// Per i logger del sistema di default equivale a SetExitFunc(), e vale quindi per l'intero processo.
+func (l *Logger) SetExitFunc(f ExitFunc) {
+	l.getDispatcher().SetExitFunc(f)
+}
+
+// Imposta il tempo massimo concesso agli hook OnFatal del sistema del logger.
+// Per i logger del sistema di default equivale a SetFatalHooksTimeout(), e vale quindi per l'intero processo.
+func (l *Logger) SetFatalHooksTimeout(timeout time.Duration) {
+	l.getDispatcher().SetFatalHooksTimeout(timeout)
+}
+
+func (d *dispatcher) OnFatal(hook FatalHook) {
+	d.fatalMu.Lock()
+	defer d.fatalMu.Unlock()
+
+	d.fatalHooks = append(d.fatalHooks, hook)
+}
+
+func (d *dispatcher) SetExitFunc(f ExitFunc) {
+	d.fatalMu.Lock()
+	defer d.fatalMu.Unlock()
+
+	d.exitF = f
+}
+
+func (d *dispatcher) SetFatalHooksTimeout(timeout time.Duration) {
+	d.fatalMu.Lock()
+	defer d.fatalMu.Unlock()
+
+	d.fatalHooksTimeout = timeout
+}
+
+// Gestisce una loggata fatale: invoca gli hook, arresta il dispatcher
+// attendendo lo svuotamento delle code dei writer e termina il processo.
+// Le loggate fatali concorrenti o successive (anche da parte degli hook)
+// non vengono gestite nuovamente, ma attendono il termine della prima.
+func (d *dispatcher) fatal(item *Item) {
+	first := false
+	d.fatalOnce.Do(func() {
+		first = true
+	})
+
+	if !first {
+		<-d.fatalDone
+		return
+	}
+
+	defer close(d.fatalDone)
+
+	d.fatalMu.Lock()
+	hooks := append([]FatalHook(nil), d.fatalHooks...)
+	exitF := d.exitF
+	timeout := d.fatalHooksTimeout
+	d.fatalMu.Unlock()
+
+	runFatalHooks(hooks, item, timeout)
+
+	d.Stop()
+
+	if exitF == nil {
+		exitF = os.Exit
+	}
+
+	exitF(FatalExitCode)
+}
+
+// Invoca gli hook in ordine, ignorandone i panic, attendendo al più timeout.
+func runFatalHooks(hooks []FatalHook, item *Item, timeout time.Duration) {
+	if len(hooks) == 0 {
+		return
+	}
+
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		for _, hook := range hooks {
+			func() {
+				defer func() {
+					recover()
+				}()
+
+				hook(item)
+			}()
+		}
+	}()
+
+	t := time.NewTimer(timeout)
+	defer t.Stop()
+
+	select {
+	case <-done:
+	case <-t.C:
+	}
+}
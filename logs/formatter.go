@@ -0,0 +1,85 @@
+package logs
+
+// Formattazione degli item.
+
+// Interfaccia dei formatter usati dai writer per serializzare gli item.
+// Le implementazioni devono essere thread safe, dal momento che
+// lo stesso formatter può essere invocato contemporaneamente da più goroutine.
+type Formatter interface {
+	// Appende a buf la rappresentazione dell'item (senza newline finale)
+	// e ritorna il buffer esteso.
+	Format(buf []byte, item *Item) []byte
+}
+
+// Formatter testuale, con il layout storico di sparalog:
+//
+//	2006-01-02 15:04:05.000 level [prefix] dir/file.go:42: message key=value other_key="quoted value"
+//
+// La posizione del chiamante compare solo se generata (vedi EnableLevelsCaller()).
+type TextFormatter struct {
+	// Antepone il timestamp.
+	Timestamp bool
+	// Formato del timestamp (nil = formato globale, vedi SetTimeFormat()).
+	Time *TimeFormat
+	// Accoda l'eventuale stacktrace.
+	StackTrace bool
+	// Accoda il payload dopo il messaggio, come coppie chiave=valore ordinate per chiave;
+	// i valori contenenti spazi, "=", virgolette o caratteri non stampabili vengono quotati.
+	Payload bool
+}
+
+// Ritorna un formatter testuale completo di timestamp, payload e stacktrace.
+func NewTextFormatter() *TextFormatter {
+	return &TextFormatter{
+		Timestamp:  true,
+		StackTrace: true,
+		Payload:    true,
+	}
+}
+
+func (f *TextFormatter) Format(buf []byte, i *Item) []byte {
+	if f.Timestamp {
+		if f.Time != nil {
+			buf = f.Time.AppendFormat(buf, i.Ts)
+		} else {
+			buf = i.AppendTimestamp(buf)
+		}
+		buf = append(buf, ' ')
+	}
+
+	buf = append(buf, i.Level.String()...)
+
+	if i.Prefix != "" {
+		buf = append(buf, " ["...)
+		buf = append(buf, i.Prefix...)
+		buf = append(buf, ']')
+	}
+
+	if i.Caller != nil {
+		buf = append(buf, ' ')
+		buf = i.Caller.AppendShort(buf)
+	}
+
+	buf = append(buf, ": "...)
+	buf = append(buf, i.Message...)
+
+	if f.Payload {
+		buf = appendPayload(buf, i.Payload)
+	}
+
+	if f.StackTrace && i.Stack != nil {
+		buf = append(buf, '\n')
+		buf = append(buf, i.Stack.String()...)
+		buf = append(buf, '\n') // add extra blank line
+	}
+
+	return buf
+}
+
+// Ritorna una copia del formatter con il formato del timestamp specificato.
+func (f *TextFormatter) WithTimeFormat(tf TimeFormat) Formatter {
+	c := *f
+	c.Time = &tf
+
+	return &c
+}
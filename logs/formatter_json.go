@@ -0,0 +1,35 @@
+package logs
+
+// Formatter JSON.
+
+// Formatter JSON: un oggetto per item, su singola riga (JSON Lines).
+// Gli item prodotti sono decodificabili con JSONDecoder, purché il timestamp
+// sia in formato RFC3339 (default) o Unix epoch.
+//
+//	{"ts":"2006-01-02T15:04:05.123456789Z","level":"info","prefix":"db","message":"query done","payload":{"rows":3}}
+type JSONFormatter struct {
+	// Include l'eventuale stacktrace, come oggetto strutturato.
+	StackTrace bool
+	// Formato del timestamp (nil = RFC3339 con nanosecondi nel fuso orario dell'item);
+	// i timestamp Unix epoch vengono codificati come numeri.
+	Time *TimeFormat
+}
+
+// Ritorna un formatter JSON completo di stacktrace.
+func NewJSONFormatter() *JSONFormatter {
+	return &JSONFormatter{
+		StackTrace: true,
+	}
+}
+
+func (f *JSONFormatter) Format(buf []byte, i *Item) []byte {
+	return i.appendJSON(buf, f.Time, f.StackTrace)
+}
+
+// Ritorna una copia del formatter con il formato del timestamp specificato.
+func (f *JSONFormatter) WithTimeFormat(tf TimeFormat) Formatter {
+	c := *f
+	c.Time = &tf
+
+	return &c
+}
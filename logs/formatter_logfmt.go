@@ -0,0 +1,160 @@
+package logs
+
+// Formatter logfmt (https://brandur.org/logfmt).
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Formatter logfmt: una riga di coppie chiave=valore, payload compreso.
+//
+//	ts=2006-01-02T15:04:05.000Z level=info prefix=db caller=db/query.go:42 msg="query done" rows=3
+type LogfmtFormatter struct {
+	// Accoda l'eventuale stacktrace come valore della chiave "stacktrace".
+	StackTrace bool
+	// Formato del timestamp (nil = UTC in formato RFC3339 con millisecondi).
+	Time *TimeFormat
+}
+
+// Formato di default dei timestamp logfmt.
+var logfmtTimeFormat = TimeFormat{
+	Layout: "2006-01-02T15:04:05.000Z07:00",
+}
+
+// Ritorna un formatter logfmt completo di stacktrace.
+func NewLogfmtFormatter() *LogfmtFormatter {
+	return &LogfmtFormatter{
+		StackTrace: true,
+	}
+}
+
+func (f *LogfmtFormatter) Format(buf []byte, i *Item) []byte {
+	tf := f.Time
+	if tf == nil {
+		tf = &logfmtTimeFormat
+	}
+
+	buf = append(buf, "ts="...)
+	buf = tf.AppendFormat(buf, i.Ts)
+
+	buf = append(buf, " level="...)
+	buf = append(buf, i.Level.String()...)
+
+	if i.Prefix != "" {
+		buf = append(buf, " prefix="...)
+		buf = appendLogfmtValue(buf, i.Prefix)
+	}
+
+	if i.Caller != nil {
+		buf = append(buf, " caller="...)
+		buf = appendLogfmtValue(buf, i.Caller.Short())
+	}
+
+	buf = append(buf, " msg="...)
+	buf = appendLogfmtValue(buf, i.Message)
+
+	buf = appendPayload(buf, i.Payload)
+
+	if f.StackTrace && i.Stack != nil {
+		buf = append(buf, " stacktrace="...)
+		buf = appendLogfmtValue(buf, i.Stack.String())
+	}
+
+	return buf
+}
+
+// Ritorna una copia del formatter con il formato del timestamp specificato.
+func (f *LogfmtFormatter) WithTimeFormat(tf TimeFormat) Formatter {
+	c := *f
+	c.Time = &tf
+
+	return &c
+}
+
+// Appende il payload come coppie " chiave=valore" ordinate per chiave.
+// Le chiavi vengono sanitizzate, i valori quotati ed escapati se necessario.
+func appendPayload(buf []byte, payload map[string]any) []byte {
+	for _, k := range sortedPayloadKeys(payload) {
+		buf = append(buf, ' ')
+		buf = appendLogfmtKey(buf, k)
+		buf = append(buf, '=')
+		buf = appendLogfmtValue(buf, payloadValueString(payload[k]))
+	}
+
+	return buf
+}
+
+// Ritorna le chiavi del payload in ordine alfabetico.
+func sortedPayloadKeys(payload map[string]any) []string {
+	if len(payload) == 0 {
+		return nil
+	}
+
+	keys := make([]string, 0, len(payload))
+	for k := range payload {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+// Converte un valore del payload in stringa.
+// Error() e String() vengono invocati tramite fmt.Sprint(), che gestisce
+// i puntatori nil e gli eventuali panic dei metodi.
+func payloadValueString(v any) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	}
+
+	return fmt.Sprint(v)
+}
+
+// Appende una chiave, sostituendo i caratteri non ammessi con "_".
+func appendLogfmtKey(buf []byte, k string) []byte {
+	if k == "" {
+		return append(buf, '_')
+	}
+
+	for _, r := range k {
+		if r <= ' ' || r == '=' || r == '"' || r == utf8.RuneError || !unicode.IsPrint(r) {
+			r = '_'
+		}
+		buf = utf8.AppendRune(buf, r)
+	}
+
+	return buf
+}
+
+// Appende un valore, quotandolo solo se necessario.
+func appendLogfmtValue(buf []byte, s string) []byte {
+	if needsQuoting(s) {
+		return strconv.AppendQuote(buf, s)
+	}
+
+	return append(buf, s...)
+}
+
+// Ritorna true se la stringa è vuota o contiene spazi, "=", virgolette
+// o caratteri non stampabili.
+func needsQuoting(s string) bool {
+	if s == "" {
+		return true
+	}
+
+	for _, r := range s {
+		if r <= ' ' || r == '=' || r == '"' || r == utf8.RuneError || !unicode.IsPrint(r) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -8,70 +8,140 @@ import (
 	"github.com/modulo-srl/sparalog/env"
 )
 
+// Item del logger.
+// Gli item generati dalle funzioni di log provengono da un pool e vengono riciclati
+// una volta inviati a tutti i writer, se questi lo consentono (vedi PoolSafeWriter):
+// i writer che li utilizzano dopo il ritorno di Write() devono acquisirne un riferimento
+// (vedi Retain() e Release()).
+// Il timestamp renderizzato, in precedenza il campo Timestamp, è ora restituito
+// dal metodo Timestamp() secondo il formato impostato con SetTimeFormat();
+// analogamente lo stacktrace, in precedenza il campo StackTrace, è ora strutturato
+// nel campo Stack e renderizzato dal metodo StackTrace().
 type Item struct {
-	Ts        time.Time
-	Timestamp string // renderizzata
+	Ts time.Time
 
 	Level Level
 
 	Prefix, Message string
 
-	StackTrace string
+	// Stacktrace strutturato, nil se non generato.
+	Stack *env.Stack
+
+	// Posizione della chiamata che ha generato l'item, nil se non generata
+	// (vedi EnableLevelsCaller()).
+	Caller *env.Frame
 
+	// Può essere condiviso con il logger che ha generato l'item:
+	// va modificato solo tramite SetPayload().
 	Payload map[string]any
+
+	// Il payload è condiviso con il logger, e va copiato prima di essere modificato.
+	sharedPayload bool
+
+	// Riferimenti detenuti sull'item, se proveniente dal pool.
+	refs   int32
+	pooled bool
+}
+
+// Genera un nuovo item con timestamp corrente, eventuale stacktrace ed eventuale posizione del chiamante.
+// Stacktrace e posizione del chiamante seguono la configurazione del sistema s.
+func newItem(s *System, level Level, prefix, msg string, stackCallsToSkip int) *Item {
+	item := newBareItem(level, prefix, msg)
+	item.generateLocation(s, 1+stackCallsToSkip)
+
+	return item
 }
 
-// Genera un nuovo item con timestamp corrente ed eventuale stacktrace.
-func newItem(level Level, prefix, msg string, stackCallsToSkip int) *Item {
-	ts := time.Now()
-	item := &Item{
-		Ts:        ts,
-		Timestamp: ts.UTC().Format("2006-01-02 15:04:05.000"),
-		Level:     level,
-		Prefix:    prefix,
-		Message:   msg,
+// Genera stacktrace e posizione del chiamante, se abilitati nel sistema per il livello dell'item.
+func (i *Item) generateLocation(s *System, stackCallsToSkip int) {
+	if s.levelStackTrace(i.Level) {
+		i.GenerateStackTrace(1 + stackCallsToSkip)
 	}
 
-	if levelsStackTrace[level] {
-		item.GenerateStackTrace(1 + stackCallsToSkip)
+	if s.levelCaller(i.Level) {
+		// Se disponibile riusa il primo frame dello stacktrace.
+		if i.Stack != nil && len(i.Stack.Frames) > 0 {
+			frame := i.Stack.Frames[0]
+			i.Caller = &frame
+		} else {
+			i.Caller = env.CaptureCaller(1 + stackCallsToSkip)
+		}
 	}
+}
+
+// Come newItem(), ma preleva l'item dal pool: va rilasciato con Release()
+// dopo averlo inviato al dispatcher.
+func newPooledItem(s *System, level Level, prefix, msg string, stackCallsToSkip int) *Item {
+	item := getPooledItem()
+	item.Ts = now()
+	item.Level = level
+	item.Prefix = prefix
+	item.Message = msg
+
+	item.generateLocation(s, 1+stackCallsToSkip)
 
 	return item
 }
 
+// Genera un nuovo item con timestamp corrente, senza stacktrace.
+func newBareItem(level Level, prefix, msg string) *Item {
+	return &Item{
+		Ts:      now(),
+		Level:   level,
+		Prefix:  prefix,
+		Message: msg,
+	}
+}
+
+// Ritorna il timestamp renderizzato secondo il formato globale (vedi SetTimeFormat()),
+// di default UTC nella forma "2006-01-02 15:04:05.000".
+func (i *Item) Timestamp() string {
+	return string(i.AppendTimestamp(nil))
+}
+
+// Appende a buf il timestamp renderizzato secondo il formato globale,
+// senza allocazioni se buf ha capacità sufficiente.
+func (i *Item) AppendTimestamp(buf []byte) []byte {
+	return getTimeFormat().AppendFormat(buf, i.Ts)
+}
+
 // GenerateStackTrace assign the stack trace of current position to the item.
 func (i *Item) GenerateStackTrace(callsToSkip int) {
-	i.StackTrace = env.StackTrace(callsToSkip + 1)
+	i.Stack = env.CaptureStack(callsToSkip + 1)
+}
+
+// Ritorna lo stacktrace renderizzato, o una stringa vuota se assente.
+func (i *Item) StackTrace() string {
+	if i.Stack == nil {
+		return ""
+	}
+
+	return i.Stack.String()
 }
 
 // Setta un valore del payload.
 func (i *Item) SetPayload(key string, value any) {
 	if i.Payload == nil {
 		i.Payload = make(map[string]any)
+	} else if i.sharedPayload {
+		payload := make(map[string]any, len(i.Payload)+1)
+		for k, v := range i.Payload {
+			payload[k] = v
+		}
+		i.Payload = payload
 	}
+	i.sharedPayload = false
 
 	i.Payload[key] = value
 }
 
-// Formatta l'item sottoforma di stringa.
+// Formatta l'item sottoforma di stringa, payload compreso, secondo il layout di TextFormatter.
 func (i Item) ToString(timestamp, stacktrace bool) string {
-	s := ""
-
-	if timestamp {
-		s = i.Timestamp + " "
-	}
-
-	s += LevelsString[i.Level]
-
-	if i.Prefix != "" {
-		s += " [" + i.Prefix + "]"
-	}
-
-	s += ": " + i.Message
-
-	if stacktrace && i.StackTrace != "" {
-		s += "\n" + i.StackTrace + "\n" // add extra blank line
+	f := TextFormatter{
+		Timestamp:  timestamp,
+		StackTrace: stacktrace,
+		Payload:    true,
 	}
 
-	return s
+	return string(f.Format(nil, &i))
 }
@@ -0,0 +1,193 @@
+package logs
+
+// Codifica e decodifica JSON degli item.
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/modulo-srl/sparalog/env"
+)
+
+// Rappresentazione JSON di un item.
+type jsonItem struct {
+	Ts      json.RawMessage            `json:"ts"`
+	Level   string                     `json:"level"`
+	Prefix  string                     `json:"prefix,omitempty"`
+	Message string                     `json:"message"`
+	Caller  *env.Frame                 `json:"caller,omitempty"`
+	Stack   *env.Stack                 `json:"stack,omitempty"`
+	Payload map[string]json.RawMessage `json:"payload,omitempty"`
+}
+
+// Codifica l'item come oggetto JSON su singola riga.
+// I valori del payload non serializzabili (canali, funzioni, strutture cicliche, ...)
+// vengono sostituiti da una stringa descrittiva, senza far fallire l'intero item.
+func (i Item) MarshalJSON() ([]byte, error) {
+	return i.appendJSON(nil, nil, true), nil
+}
+
+// Decodifica un item precedentemente codificato con MarshalJSON().
+// I valori del payload vengono decodificati nei tipi generici di encoding/json.
+func (i *Item) UnmarshalJSON(data []byte) error {
+	var ji jsonItem
+
+	err := json.Unmarshal(data, &ji)
+	if err != nil {
+		return err
+	}
+
+	ts, err := parseJSONTimestamp(ji.Ts)
+	if err != nil {
+		return fmt.Errorf("invalid ts: %w", err)
+	}
+
+	level, err := ParseLevel(ji.Level)
+	if err != nil {
+		return err
+	}
+
+	*i = Item{
+		Ts:      ts,
+		Level:   level,
+		Prefix:  ji.Prefix,
+		Message: ji.Message,
+		Caller:  ji.Caller,
+		Stack:   ji.Stack,
+	}
+
+	for k, raw := range ji.Payload {
+		var v any
+
+		err = json.Unmarshal(raw, &v)
+		if err != nil {
+			return fmt.Errorf("invalid payload %q: %w", k, err)
+		}
+
+		i.SetPayload(k, v)
+	}
+
+	return nil
+}
+
+// Appende a buf la codifica JSON dell'item, con il timestamp nel formato tf (nil = RFC3339).
+func (i *Item) appendJSON(buf []byte, tf *TimeFormat, stacktrace bool) []byte {
+	var ts []byte
+
+	switch {
+	case tf == nil:
+		ts = strconv.AppendQuote(nil, i.Ts.Format(time.RFC3339Nano))
+	case tf.Epoch != EpochNone:
+		ts = tf.AppendFormat(nil, i.Ts)
+	default:
+		ts = strconv.AppendQuote(nil, tf.Format(i.Ts))
+	}
+
+	ji := jsonItem{
+		Ts:      ts,
+		Level:   i.Level.String(),
+		Prefix:  i.Prefix,
+		Message: i.Message,
+		Caller:  i.Caller,
+	}
+
+	if stacktrace {
+		ji.Stack = i.Stack
+	}
+
+	if len(i.Payload) > 0 {
+		ji.Payload = make(map[string]json.RawMessage, len(i.Payload))
+
+		for k, v := range i.Payload {
+			ji.Payload[k] = marshalPayloadValue(v)
+		}
+	}
+
+	// Non può fallire: tutti i campi sono stringhe o JSON già validato.
+	bb, _ := json.Marshal(&ji)
+
+	return append(buf, bb...)
+}
+
+// Decodifica un timestamp RFC3339 o Unix epoch; la precisione dell'epoch
+// viene dedotta dall'ordine di grandezza (secondi, millisecondi, microsecondi o nanosecondi).
+func parseJSONTimestamp(raw json.RawMessage) (time.Time, error) {
+	var s string
+
+	if json.Unmarshal(raw, &s) == nil {
+		return time.Parse(time.RFC3339Nano, s)
+	}
+
+	var n int64
+
+	err := json.Unmarshal(raw, &n)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	abs := n
+	if abs < 0 {
+		abs = -abs
+	}
+
+	switch {
+	case abs < 1e11:
+		return time.Unix(n, 0), nil
+	case abs < 1e14:
+		return time.UnixMilli(n), nil
+	case abs < 1e17:
+		return time.UnixMicro(n), nil
+	}
+
+	return time.Unix(0, n), nil
+}
+
+// Codifica un singolo valore del payload; se non serializzabile
+// ritorna una stringa JSON che ne descrive il tipo e l'errore.
+func marshalPayloadValue(v any) (raw json.RawMessage) {
+	defer func() {
+		// Marshaler custom che vanno in panic.
+		if r := recover(); r != nil {
+			raw = unserializableValue(v, fmt.Errorf("panic: %v", r))
+		}
+	}()
+
+	bb, err := json.Marshal(v)
+	if err != nil {
+		return unserializableValue(v, err)
+	}
+
+	return bb
+}
+
+func unserializableValue(v any, err error) json.RawMessage {
+	bb, _ := json.Marshal(fmt.Sprintf("!unserializable %T: %s", v, err))
+	return bb
+}
+
+// Decoder di item codificati in JSON Lines (un oggetto JSON per riga).
+type JSONDecoder struct {
+	dec *json.Decoder
+}
+
+// Ritorna un decoder che legge gli item da r.
+func NewJSONDecoder(r io.Reader) *JSONDecoder {
+	return &JSONDecoder{
+		dec: json.NewDecoder(r),
+	}
+}
+
+// Decodifica il prossimo item; ritorna io.EOF al termine dello stream.
+func (d *JSONDecoder) Decode() (*Item, error) {
+	var item Item
+
+	err := d.dec.Decode(&item)
+	if err != nil {
+		return nil, err
+	}
+
+	return &item, nil
+}
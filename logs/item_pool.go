@@ -0,0 +1,55 @@
+package logs
+
+// Pool degli item generati dai logger.
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+var itemsPool = sync.Pool{
+	New: func() any {
+		return &Item{}
+	},
+}
+
+// Preleva dal pool un item azzerato, con un riferimento detenuto dal chiamante.
+func getPooledItem() *Item {
+	i := itemsPool.Get().(*Item)
+	i.refs = 1
+	i.pooled = true
+
+	return i
+}
+
+// Acquisisce un riferimento all'item, impedendone il riciclo finché non viene rilasciato con Release().
+// Va invocata dai writer che usano l'item dopo il ritorno di Write() (es. accodandolo),
+// e nel caso in cui l'item venga conservato indefinitamente (Retain() senza Release()
+// esclude semplicemente l'item dal riciclo).
+// Non ha effetto sugli item non provenienti dal pool (es. generati con NewItem()).
+func (i *Item) Retain() {
+	if i.pooled {
+		atomic.AddInt32(&i.refs, 1)
+	}
+}
+
+// Rilascia un riferimento acquisito con Retain(): al rilascio dell'ultimo riferimento
+// l'item viene azzerato e restituito al pool, e non va più utilizzato.
+// Non ha effetto sugli item non provenienti dal pool.
+func (i *Item) Release() {
+	if !i.pooled {
+		return
+	}
+
+	n := atomic.AddInt32(&i.refs, -1)
+	if n > 0 {
+		return
+	}
+	if n < 0 {
+		panic("sparalog: item released too many times")
+	}
+
+	// Il payload è condiviso con il logger: viene solo dereferenziato.
+	*i = Item{}
+	itemsPool.Put(i)
+}
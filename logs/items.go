@@ -2,26 +2,37 @@ package logs
 
 // Funzioni per la generazione item generici (generati dal logger di default).
 
+import "fmt"
+
+// Genera un nuovo item senza payload, stacktrace né posizione del chiamante,
+// non legato ad alcun sistema (es. per i feedback dei writer, vedi Writer.SetFeedbackChan()).
+func NewBareItem(level Level, prefix, msg string) *Item {
+	return newBareItem(level, prefix, msg)
+}
+
 // Genera un nuovo item dal logger di default, di livello specifico.
 // Eredita una copia del payload dal logger che può essere ulteriormente customizzata.
 func NewItem(level Level, args ...any) *Item {
-	return defaultLogger.NewItem(level, args...)
+	return defaultLogger().newSelfItem(level, 1, fmt.Sprint(args...))
 }
 
 // Genera un nuovo item dal logger di default, di livello specifico.
 // Eredita una copia del payload dal logger che può essere ulteriormente customizzata.
 func NewItemf(level Level, format string, args ...any) *Item {
-	return defaultLogger.NewItemf(level, format, args...)
+	return defaultLogger().newSelfItem(level, 1, fmt.Sprintf(format, args...))
 }
 
 // Genera un nuovo item dal logger di default, di livello errore.
 // Eredita una copia del payload dal logger che può essere ulteriormente customizzata.
 func NewErrorItem(err error) *Item {
-	return defaultLogger.NewErrorItem(err)
+	item := defaultLogger().newSelfItem(ErrorLevel, 1, err.Error())
+	item.SetError(err)
+
+	return item
 }
 
 // Genera un nuovo item dal logger di default, di livello errore.
 // Eredita una copia del payload dal logger che può essere ulteriormente customizzata.
 func NewErrorItemf(format string, a ...any) *Item {
-	return defaultLogger.NewErrorItemf(format, a...)
+	return defaultLogger().newSelfItem(ErrorLevel, 1, fmt.Errorf(format, a...).Error())
 }
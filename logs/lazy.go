@@ -0,0 +1,120 @@
+package logs
+
+// Valutazione differita dei messaggi, per non pagarne il costo quando il livello non è loggabile.
+
+import "fmt"
+
+// Ritorna true se il livello è loggabile dal logger (non mutato e con almeno un writer,
+// tenendo conto delle regole di livello); utile per evitare di calcolare diagnostiche costose.
+func (l *Logger) Enabled(level Level) bool {
+	return l.canDispatch(l.getDispatcher(), level)
+}
+
+// Ritorna true se il livello è loggabile dal logger di default.
+func Enabled(level Level) bool {
+	return defaultLogger().Enabled(level)
+}
+
+// Ritorna un fmt.Stringer che invoca f solo quando viene convertito in stringa,
+// ovvero solo se la loggata viene effettivamente generata:
+//
+//	logs.Debug("state: ", logs.Lazy(dumpState))
+func Lazy(f func() string) fmt.Stringer {
+	return lazyStringer(f)
+}
+
+type lazyStringer func() string
+
+func (f lazyStringer) String() string {
+	return f()
+}
+
+// Logga a livello fatale effetuando anche os.Exit(FatalExitCode), invocando f per generare il messaggio solo se il livello è loggabile.
+func (l *Logger) FatalFunc(f func() string) {
+	l.logFuncDepth(nil, FatalLevel, 1, f)
+}
+
+// Logga a livello critico, invocando f per generare il messaggio solo se il livello è loggabile.
+func (l *Logger) CriticalFunc(f func() string) {
+	l.logFuncDepth(nil, CriticalLevel, 1, f)
+}
+
+// Logga a livello errore, invocando f per generare il messaggio solo se il livello è loggabile.
+func (l *Logger) ErrorFunc(f func() string) {
+	l.logFuncDepth(nil, ErrorLevel, 1, f)
+}
+
+// Logga a livello warning, invocando f per generare il messaggio solo se il livello è loggabile.
+func (l *Logger) WarningFunc(f func() string) {
+	l.logFuncDepth(nil, WarningLevel, 1, f)
+}
+
+// Logga a livello notice, invocando f per generare il messaggio solo se il livello è loggabile.
+func (l *Logger) NoticeFunc(f func() string) {
+	l.logFuncDepth(nil, NoticeLevel, 1, f)
+}
+
+// Logga a livello info, invocando f per generare il messaggio solo se il livello è loggabile.
+func (l *Logger) InfoFunc(f func() string) {
+	l.logFuncDepth(nil, InfoLevel, 1, f)
+}
+
+// Logga a livello debug, invocando f per generare il messaggio solo se il livello è loggabile.
+func (l *Logger) DebugFunc(f func() string) {
+	l.logFuncDepth(nil, DebugLevel, 1, f)
+}
+
+// Logga a livello trace, invocando f per generare il messaggio solo se il livello è loggabile.
+func (l *Logger) TraceFunc(f func() string) {
+	l.logFuncDepth(nil, TraceLevel, 1, f)
+}
+
+// Logga a un livello qualsiasi, invocando f per generare il messaggio solo se il livello è loggabile.
+func (l *Logger) LogFunc(level Level, f func() string) {
+	l.logFuncDepth(nil, level, 1, f)
+}
+
+// Logga a livello fatale effetuando anche os.Exit(FatalExitCode), invocando f per generare il messaggio solo se il livello è loggabile.
+func FatalFunc(f func() string) {
+	defaultLogger().logFuncDepth(nil, FatalLevel, 1, f)
+}
+
+// Logga a livello critico, invocando f per generare il messaggio solo se il livello è loggabile.
+func CriticalFunc(f func() string) {
+	defaultLogger().logFuncDepth(nil, CriticalLevel, 1, f)
+}
+
+// Logga a livello errore, invocando f per generare il messaggio solo se il livello è loggabile.
+func ErrorFunc(f func() string) {
+	defaultLogger().logFuncDepth(nil, ErrorLevel, 1, f)
+}
+
+// Logga a livello warning, invocando f per generare il messaggio solo se il livello è loggabile.
+func WarningFunc(f func() string) {
+	defaultLogger().logFuncDepth(nil, WarningLevel, 1, f)
+}
+
+// Logga a livello notice, invocando f per generare il messaggio solo se il livello è loggabile.
+func NoticeFunc(f func() string) {
+	defaultLogger().logFuncDepth(nil, NoticeLevel, 1, f)
+}
+
+// Logga a livello info, invocando f per generare il messaggio solo se il livello è loggabile.
+func InfoFunc(f func() string) {
+	defaultLogger().logFuncDepth(nil, InfoLevel, 1, f)
+}
+
+// Logga a livello debug, invocando f per generare il messaggio solo se il livello è loggabile.
+func DebugFunc(f func() string) {
+	defaultLogger().logFuncDepth(nil, DebugLevel, 1, f)
+}
+
+// Logga a livello trace, invocando f per generare il messaggio solo se il livello è loggabile.
+func TraceFunc(f func() string) {
+	defaultLogger().logFuncDepth(nil, TraceLevel, 1, f)
+}
+
+// Logga a un livello qualsiasi, invocando f per generare il messaggio solo se il livello è loggabile.
+func LogFunc(level Level, f func() string) {
+	defaultLogger().logFuncDepth(nil, level, 1, f)
+}
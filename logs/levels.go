@@ -2,6 +2,14 @@ package logs
 
 // Livelli di log.
 
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"sync/atomic"
+)
+
 // Level type.
 type Level int
 
@@ -25,39 +33,213 @@ const (
 	// Mutato di default.
 	DebugLevel
 
+	// TraceLevel - Tracciatura di dettaglio, più verbosa del debug.
+	// Mutato di default.
+	TraceLevel
+	// NoticeLevel - Eventi normali ma significativi, tra info e warning.
+	NoticeLevel
+	// CriticalLevel - Condizioni critiche che richiedono un intervento immediato, tra error e fatal,
+	// senza terminare l'applicazione.
+	// Stack trace enabled by default.
+	CriticalLevel
+
+	// Numero dei livelli predefiniti; i livelli registrati con RegisterLevel() seguono.
+	//
+	// Deprecated: non include i livelli registrati, usare len(Levels).
 	LevelsCount
 )
 
-// Levels is a constant of all logging levels.
-var Levels = [LevelsCount]Level{
+// Proprietà di un livello.
+type LevelInfo struct {
+	// Nome del livello, usato nelle loggate e da ParseLevel().
+	Name string
+	// Icona UTF8.
+	Icon string
+
+	// Verbosità del livello: i livelli con rank inferiore sono più gravi.
+	// Determina l'ordinamento dei livelli nelle soglie (vedi SetLoggersLevels()).
+	Rank int
+
+	// Severità syslog (RFC 5424: 0 = emergency ... 7 = debug).
+	SyslogSeverity int
+
+	// Il livello viene scritto su stderr anziché su stdout.
+	Stderr bool
+
+	// Il livello è critico (vedi CriticalLevels).
+	Critical bool
+}
+
+// Proprietà dei livelli predefiniti, indicizzate per livello.
+var predefinedLevelsInfo = []LevelInfo{
+	FatalLevel:    {Name: "fatal", Icon: "\xE2\x9D\x8C", Rank: 0, SyslogSeverity: 2, Stderr: true, Critical: true},
+	ErrorLevel:    {Name: "error", Icon: "\xE2\x9D\x97", Rank: 20, SyslogSeverity: 3, Stderr: true, Critical: true},
+	WarningLevel:  {Name: "warning", Icon: "\xE2\x9A\xA0", Rank: 30, SyslogSeverity: 4, Stderr: true, Critical: true},
+	InfoLevel:     {Name: "info", Icon: "\xE2\x84\xB9", Rank: 50, SyslogSeverity: 6},
+	DebugLevel:    {Name: "debug", Icon: "\xF0\x9F\x90\x9B", Rank: 60, SyslogSeverity: 7},
+	TraceLevel:    {Name: "trace", Icon: "\xF0\x9F\x94\x8E", Rank: 70, SyslogSeverity: 7},
+	NoticeLevel:   {Name: "notice", Icon: "\xF0\x9F\x93\x8C", Rank: 40, SyslogSeverity: 5},
+	CriticalLevel: {Name: "critical", Icon: "\xF0\x9F\x94\xA5", Rank: 10, SyslogSeverity: 2, Stderr: true, Critical: true},
+}
+
+// Levels is a constant of all logging levels, including the registered ones.
+var Levels = []Level{
 	FatalLevel,
 	ErrorLevel,
 	WarningLevel,
 	InfoLevel,
 	DebugLevel,
+	TraceLevel,
+	NoticeLevel,
+	CriticalLevel,
 }
 
 // CriticalLevels lists critical levels.
-var CriticalLevels = []Level{FatalLevel, ErrorLevel, WarningLevel}
+var CriticalLevels = []Level{FatalLevel, CriticalLevel, ErrorLevel, WarningLevel}
 
 // LevelsString is a constant of all logging levels names.
-var LevelsString = [LevelsCount]string{
-	"fatal", "error", "warning", "info", "debug",
+var LevelsString = []string{
+	"fatal", "error", "warning", "info", "debug", "trace", "notice", "critical",
 }
 
 // LevelsIcons is a constant of all logging levels UTF8 icons.
-var LevelsIcons = [LevelsCount]string{
-	"\xE2\x9D\x8C", "\xE2\x9D\x97", "\xE2\x9A\xA0", "\xE2\x84\xB9", "\xF0\x9F\x90\x9B", /*"\xF0\x9F\x94\x8E",*/
+var LevelsIcons = []string{
+	"\xE2\x9D\x8C", "\xE2\x9D\x97", "\xE2\x9A\xA0", "\xE2\x84\xB9", "\xF0\x9F\x90\x9B", "\xF0\x9F\x94\x8E", "\xF0\x9F\x93\x8C", "\xF0\x9F\x94\xA5",
 }
 
-// Attiva lo stacktrace per specifici livelli.
-// NON thread safe.
-func EnableLevelsStackTrace(levels []Level) {
-	for level := 0; level < int(LevelsCount); level++ {
-		levelsStackTrace[level] = false
+// Proprietà di tutti i livelli, indicizzate per livello: sostituite da RegisterLevel()
+// con una copia estesa e mai modificate, vengono lette senza lock.
+var levelsInfo atomic.Pointer[[]LevelInfo]
+
+// Serializza le registrazioni dei livelli.
+var levelsMu sync.Mutex
+
+// Numero dei livelli critici predefiniti, in testa a CriticalLevels.
+var predefinedCriticalLevels = len(CriticalLevels)
+
+func init() {
+	levelsInfo.Store(&predefinedLevelsInfo)
+}
+
+// Ritorna le proprietà di tutti i livelli, da non modificare.
+func loadLevelsInfo() []LevelInfo {
+	return *levelsInfo.Load()
+}
+
+// Ritorna il numero dei livelli, inclusi quelli registrati.
+func levelsCount() int {
+	return len(loadLevelsInfo())
+}
+
+// Registra un nuovo livello, aggiornando Levels, LevelsString, LevelsIcons
+// ed eventualmente CriticalLevels; ritorna errore se il nome è vuoto o già in uso,
+// se Rank non è positivo (rank 0 è riservato al fatal) o se SyslogSeverity non è
+// compresa tra 1 (alert) e 7 (debug).
+// Il nuovo livello non è associato ad alcun writer, salvo quelli associati a tutti i livelli,
+// non è mutato e non ha lo stacktrace abilitato.
+// Invocabile anche mentre i logger sono in uso; le variabili esportate vengono però
+// sostituite con copie estese senza sincronizzazione: vanno lette solo dopo aver registrato
+// i livelli (tipicamente registrati in fase di inizializzazione, prima di loggare).
+func RegisterLevel(info LevelInfo) (Level, error) {
+	if info.Name == "" {
+		return 0, errors.New("empty level name")
+	}
+	if info.Rank <= 0 {
+		return 0, fmt.Errorf("level %q: rank must be positive", info.Name)
+	}
+	if info.SyslogSeverity < 1 || info.SyslogSeverity > 7 {
+		return 0, fmt.Errorf("level %q: syslog severity must be between 1 and 7", info.Name)
+	}
+
+	levelsMu.Lock()
+	defer levelsMu.Unlock()
+
+	if _, err := ParseLevel(info.Name); err == nil {
+		return 0, fmt.Errorf("level %q already registered", info.Name)
 	}
 
-	for _, level := range levels {
-		levelsStackTrace[level] = true
+	infos := loadLevelsInfo()
+	level := Level(len(infos))
+
+	// Le copie delle variabili lette in precedenza restano valide.
+	Levels = append(Levels[:len(Levels):len(Levels)], level)
+	LevelsString = append(LevelsString[:len(LevelsString):len(LevelsString)], info.Name)
+	LevelsIcons = append(LevelsIcons[:len(LevelsIcons):len(LevelsIcons)], info.Icon)
+
+	if info.Critical {
+		CriticalLevels = append(CriticalLevels[:len(CriticalLevels):len(CriticalLevels)], level)
+	}
+
+	infos = append(infos[:len(infos):len(infos)], info)
+	levelsInfo.Store(&infos)
+
+	return level, nil
+}
+
+// Rimuove i livelli registrati con RegisterLevel(), ripristinando i soli livelli predefiniti.
+// Pensato per i test (es. t.Cleanup(logs.ResetLevels)): i valori dei livelli rimossi
+// vengono riassegnati dalle registrazioni successive, e i logger in uso li trattano
+// come livelli sconosciuti.
+func ResetLevels() {
+	levelsMu.Lock()
+	defer levelsMu.Unlock()
+
+	n := len(predefinedLevelsInfo)
+
+	Levels = Levels[:n:n]
+	LevelsString = LevelsString[:n:n]
+	LevelsIcons = LevelsIcons[:n:n]
+	CriticalLevels = CriticalLevels[:predefinedCriticalLevels:predefinedCriticalLevels]
+
+	levelsInfo.Store(&predefinedLevelsInfo)
+}
+
+// Ritorna le proprietà del livello.
+func (l Level) Info() LevelInfo {
+	infos := loadLevelsInfo()
+
+	if l < 0 || int(l) >= len(infos) {
+		return LevelInfo{
+			Name: fmt.Sprintf("level%d", int(l)),
+			Rank: math.MaxInt,
+
+			SyslogSeverity: 7,
+		}
 	}
+
+	return infos[l]
+}
+
+// Ritorna il nome del livello.
+func (l Level) String() string {
+	return l.Info().Name
+}
+
+// Ritorna true se il livello è più grave di other (ha rank inferiore).
+func (l Level) MoreSevere(other Level) bool {
+	return l.Info().Rank < other.Info().Rank
+}
+
+// Ritorna il livello corrispondente al nome (vedi LevelsString).
+func ParseLevel(name string) (Level, error) {
+	for level, info := range loadLevelsInfo() {
+		if info.Name == name {
+			return Level(level), nil
+		}
+	}
+
+	return 0, fmt.Errorf("unknown level %q", name)
+}
+
+// Attiva lo stacktrace per specifici livelli del sistema di default.
+// Thread safe (vedi System.EnableLevelsStackTrace()).
+func EnableLevelsStackTrace(levels []Level) {
+	DefaultSystem().EnableLevelsStackTrace(levels)
+}
+
+// Attiva la posizione del chiamante (file, riga e funzione) per specifici livelli del sistema di default:
+// molto più economica dello stacktrace, dal momento che viene risolto un solo frame.
+// Thread safe (vedi System.EnableLevelsCaller()).
+func EnableLevelsCaller(levels []Level) {
+	DefaultSystem().EnableLevelsCaller(levels)
 }
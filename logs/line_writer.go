@@ -0,0 +1,165 @@
+package logs
+
+// Adattatore io.Writer per i package che producono output testuale (ad esempio il package log standard).
+
+import (
+	"bytes"
+	"log"
+	"strings"
+	"sync"
+)
+
+// io.Writer che suddivide l'input in righe e logga ciascuna riga come item.
+// Le righe incomplete vengono mantenute fino al successivo "\n" o a Flush().
+type LineWriter struct {
+	logger *Logger
+	level  Level
+
+	// Rileva il livello dal prefisso della riga ("ERROR:", "[warn]", ...).
+	detectLevels bool
+
+	// Chiamate tra l'applicativo e Write() o Flush(), da escludere
+	// da stacktrace e posizione del chiamante (es. quelle del package log, vedi RedirectStdLog()).
+	callDepth int
+
+	mu  sync.Mutex
+	buf []byte
+}
+
+// Prefissi riconosciuti da DetectLevels(), in minuscolo.
+var lineLevelPrefixes = []struct {
+	prefix string
+	level  Level
+}{
+	{"critical", CriticalLevel},
+	{"error", ErrorLevel},
+	{"warning", WarningLevel},
+	{"warn", WarningLevel},
+	{"notice", NoticeLevel},
+	{"info", InfoLevel},
+	{"debug", DebugLevel},
+	{"trace", TraceLevel},
+}
+
+// Alloca un io.Writer che logga ogni riga ricevuta tramite il logger specificato
+// (il logger di default se nil).
+// - level: livello delle loggate generate.
+func NewLineWriter(l *Logger, level Level) *LineWriter {
+	if l == nil {
+		l = defaultLogger()
+	}
+
+	return &LineWriter{
+		logger: l,
+		level:  level,
+	}
+}
+
+// Abilita il riconoscimento del livello dal prefisso di ogni riga,
+// nelle forme "ERROR:" o "[error]" (senza distinzione tra maiuscole e minuscole)
+// per i livelli critical, error, warning/warn, notice, info, debug e trace.
+// Il prefisso riconosciuto viene rimosso dal messaggio;
+// le righe senza prefisso riconosciuto vengono loggate al livello di default.
+func (w *LineWriter) DetectLevels(enable bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.detectLevels = enable
+}
+
+func (w *LineWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.buf = append(w.buf, p...)
+
+	for {
+		i := bytes.IndexByte(w.buf, '\n')
+		if i < 0 {
+			break
+		}
+
+		w.logLine(string(w.buf[:i]), w.callDepth+1)
+		w.buf = w.buf[i+1:]
+	}
+
+	// Evita di trattenere il buffer dell'ultima scrittura.
+	if len(w.buf) == 0 {
+		w.buf = nil
+	}
+
+	return len(p), nil
+}
+
+// Logga l'eventuale riga incompleta rimasta nel buffer.
+func (w *LineWriter) Flush() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if len(w.buf) > 0 {
+		w.logLine(string(w.buf), w.callDepth+1)
+		w.buf = nil
+	}
+}
+
+// Va invocata con w.mu acquisito.
+//   - depth: numero di chiamate tra l'applicativo e logLine(), da escludere dallo stacktrace.
+func (w *LineWriter) logLine(line string, depth int) {
+	line = strings.TrimRight(line, "\r")
+	if line == "" {
+		return
+	}
+
+	level := w.level
+
+	if w.detectLevels {
+		level, line = detectLineLevel(line, level)
+	}
+
+	w.logger.logDepth(nil, level, depth+1, line)
+}
+
+// Ritorna il livello indicato dal prefisso della riga e la riga senza prefisso,
+// oppure il livello di default e la riga originale.
+func detectLineLevel(line string, level Level) (Level, string) {
+	for _, lp := range lineLevelPrefixes {
+		n := len(lp.prefix)
+
+		// "[error]"
+		if len(line) >= n+2 && line[0] == '[' && line[n+1] == ']' &&
+			strings.EqualFold(line[1:n+1], lp.prefix) {
+			return lp.level, strings.TrimLeft(line[n+2:], " ")
+		}
+
+		// "ERROR:"
+		if len(line) > n && line[n] == ':' &&
+			strings.EqualFold(line[:n], lp.prefix) {
+			return lp.level, strings.TrimLeft(line[n+1:], " ")
+		}
+	}
+
+	return level, line
+}
+
+// Redirige l'output del package log standard verso il logger di default,
+// al livello specificato e con riconoscimento del livello dal prefisso delle righe.
+// Data e ora vengono rimossi dalle righe, essendo già presenti negli item.
+// Ritorna una funzione che ripristina output e flag precedenti.
+func RedirectStdLog(level Level) (restore func()) {
+	w := NewLineWriter(nil, level)
+	w.DetectLevels(true)
+
+	// Le funzioni del package log (es. log.Printf()) scrivono tramite log.(*Logger).output().
+	w.callDepth = 2
+
+	prevWriter := log.Writer()
+	prevFlags := log.Flags()
+
+	log.SetFlags(prevFlags &^ (log.Ldate | log.Ltime | log.Lmicroseconds | log.LUTC))
+	log.SetOutput(w)
+
+	return func() {
+		log.SetOutput(prevWriter)
+		log.SetFlags(prevFlags)
+	}
+}
@@ -4,55 +4,95 @@ package logs
 
 // Logga a livello fatale effetuando anche os.Exit(FatalExitCode)
 func Fatal(args ...any) {
-	defaultLogger.Fatal(args...)
+	defaultLogger().logDepth(nil, FatalLevel, 1, args...)
 }
 
 // Logga a livello fatale effetuando anche os.Exit(FatalExitCode)
 func Fatalf(format string, args ...any) {
-	defaultLogger.Fatalf(format, args...)
+	defaultLogger().logfDepth(nil, FatalLevel, 1, format, args...)
+}
+
+// Logga a livello critico.
+func Critical(args ...any) {
+	defaultLogger().logDepth(nil, CriticalLevel, 1, args...)
+}
+
+// Logga a livello critico.
+func Criticalf(format string, args ...any) {
+	defaultLogger().logfDepth(nil, CriticalLevel, 1, format, args...)
 }
 
 // Logga a livello errore.
 func Error(args ...any) {
-	defaultLogger.Error(args...)
+	defaultLogger().logDepth(nil, ErrorLevel, 1, args...)
 }
 
 // Logga a livello errore.
 func Errorf(format string, args ...any) {
-	defaultLogger.Errorf(format, args...)
+	defaultLogger().logfDepth(nil, ErrorLevel, 1, format, args...)
 }
 
 // Logga a livello warning.
 func Warning(args ...any) {
-	defaultLogger.Warning(args...)
+	defaultLogger().logDepth(nil, WarningLevel, 1, args...)
 }
 
 // Logga a livello warning.
 func Warningf(format string, args ...any) {
-	defaultLogger.Warningf(format, args...)
+	defaultLogger().logfDepth(nil, WarningLevel, 1, format, args...)
+}
+
+// Logga a livello notice.
+func Notice(args ...any) {
+	defaultLogger().logDepth(nil, NoticeLevel, 1, args...)
+}
+
+// Logga a livello notice.
+func Noticef(format string, args ...any) {
+	defaultLogger().logfDepth(nil, NoticeLevel, 1, format, args...)
 }
 
 // Logga a livello info.
 func Info(args ...any) {
-	defaultLogger.Info(args...)
+	defaultLogger().logDepth(nil, InfoLevel, 1, args...)
 }
 
 // Logga a livello info.
 func Infof(format string, args ...any) {
-	defaultLogger.Infof(format, args...)
+	defaultLogger().logfDepth(nil, InfoLevel, 1, format, args...)
 }
 
 // Logga a livello debug.
 func Debug(args ...any) {
-	defaultLogger.Debug(args...)
+	defaultLogger().logDepth(nil, DebugLevel, 1, args...)
 }
 
 // Logga a livello debug.
 func Debugf(format string, args ...any) {
-	defaultLogger.Debugf(format, args...)
+	defaultLogger().logfDepth(nil, DebugLevel, 1, format, args...)
+}
+
+// Logga a livello trace.
+func Trace(args ...any) {
+	defaultLogger().logDepth(nil, TraceLevel, 1, args...)
+}
+
+// Logga a livello trace.
+func Tracef(format string, args ...any) {
+	defaultLogger().logfDepth(nil, TraceLevel, 1, format, args...)
+}
+
+// Logga a un livello qualsiasi, inclusi quelli registrati con RegisterLevel().
+func Log(level Level, args ...any) {
+	defaultLogger().logDepth(nil, level, 1, args...)
+}
+
+// Logga a un livello qualsiasi, inclusi quelli registrati con RegisterLevel().
+func Logf(level Level, format string, args ...any) {
+	defaultLogger().logfDepth(nil, level, 1, format, args...)
 }
 
 // Logga un item precedentemente generato.
 func LogItem(item *Item) {
-	defaultLogger.LogItem(item)
+	defaultLogger().LogItem(item)
 }
@@ -3,18 +3,34 @@ package logs
 // Logger.
 
 import (
+	"context"
 	"fmt"
 	"sync"
+	"sync/atomic"
 )
 
+// Logger genera le loggate di un sistema (vedi System).
+// I metodi di configurazione di writer, mute, throttling, fatali e ciclo di vita
+// agiscono sull'intero sistema del logger, non sul solo logger.
 type Logger struct {
+	// Sistema a cui appartiene il logger;
+	// se nil viene usato il sistema di default.
+	system *System
+
 	initItemF InitItemF
 
 	prefix string
 
-	// Quante chiamate dello stackTrace escludere di default.
-	stackCallsToSkip int
+	// Nome del logger nel registro, vuoto se non registrato.
+	name string
+
+	// Livello meno grave loggabile + 1 impostato dalle regole del registro, indipendente dai mute;
+	// 0 se nessuna regola si applica al logger.
+	// Condiviso con i logger derivati (vedi With()); nil se il logger non deriva da uno registrato.
+	maxLevel *atomic.Int32
 
+	// Il payload non viene mai modificato in place, dato che viene condiviso con gli item loggati:
+	// SetPayload() ne alloca una copia (copy on write).
 	muPayload sync.RWMutex
 	payload   map[string]any
 }
@@ -22,10 +38,11 @@ type Logger struct {
 // Alloca un nuovo logger.
 func newAliasLogger(logger *Logger, prefix string) *Logger {
 	l := Logger{
-		prefix:           prefix,
-		payload:          logger.getPayloadCopy(), // riceve una copia del payload se il logger padre ne è provvisto
-		initItemF:        logger.initItemF,
-		stackCallsToSkip: 0,
+		system:    logger.system,
+		prefix:    prefix,
+		payload:   logger.getPayload(), // condiviso, essendo immutabile
+		initItemF: logger.initItemF,
+		maxLevel:  logger.maxLevel,
 	}
 
 	return &l
@@ -38,7 +55,17 @@ func (l *Logger) Fatal(args ...any) {
 
 // Logga a livello fatale effetuando anche os.Exit(FatalExitCode)
 func (l *Logger) Fatalf(format string, args ...any) {
-	l.log(FatalLevel, fmt.Sprintf(format, args...))
+	l.logf(FatalLevel, format, args...)
+}
+
+// Logga a livello critico.
+func (l *Logger) Critical(args ...any) {
+	l.log(CriticalLevel, args...)
+}
+
+// Logga a livello critico.
+func (l *Logger) Criticalf(format string, args ...any) {
+	l.logf(CriticalLevel, format, args...)
 }
 
 // Logga a livello errore.
@@ -48,7 +75,7 @@ func (l *Logger) Error(args ...any) {
 
 // Logga a livello errore.
 func (l *Logger) Errorf(format string, args ...any) {
-	l.log(ErrorLevel, fmt.Sprintf(format, args...))
+	l.logf(ErrorLevel, format, args...)
 }
 
 // Logga a livello warning.
@@ -58,7 +85,17 @@ func (l *Logger) Warning(args ...any) {
 
 // Logga a livello warning.
 func (l *Logger) Warningf(format string, args ...any) {
-	l.log(WarningLevel, fmt.Sprintf(format, args...))
+	l.logf(WarningLevel, format, args...)
+}
+
+// Logga a livello notice.
+func (l *Logger) Notice(args ...any) {
+	l.log(NoticeLevel, args...)
+}
+
+// Logga a livello notice.
+func (l *Logger) Noticef(format string, args ...any) {
+	l.logf(NoticeLevel, format, args...)
 }
 
 // Logga a livello info.
@@ -68,7 +105,7 @@ func (l *Logger) Info(args ...any) {
 
 // Logga a livello info.
 func (l *Logger) Infof(format string, args ...any) {
-	l.log(InfoLevel, fmt.Sprintf(format, args...))
+	l.logf(InfoLevel, format, args...)
 }
 
 // Logga a livello debug.
@@ -78,7 +115,27 @@ func (l *Logger) Debug(args ...any) {
 
 // Logga a livello debug.
 func (l *Logger) Debugf(format string, args ...any) {
-	l.log(DebugLevel, fmt.Sprintf(format, args...))
+	l.logf(DebugLevel, format, args...)
+}
+
+// Logga a livello trace.
+func (l *Logger) Trace(args ...any) {
+	l.log(TraceLevel, args...)
+}
+
+// Logga a livello trace.
+func (l *Logger) Tracef(format string, args ...any) {
+	l.logf(TraceLevel, format, args...)
+}
+
+// Logga a un livello qualsiasi, inclusi quelli registrati con RegisterLevel().
+func (l *Logger) Log(level Level, args ...any) {
+	l.log(level, args...)
+}
+
+// Logga a un livello qualsiasi, inclusi quelli registrati con RegisterLevel().
+func (l *Logger) Logf(level Level, format string, args ...any) {
+	l.logf(level, format, args...)
 }
 
 // Genera un nuovo item di livello specifico.
@@ -93,10 +150,13 @@ func (l *Logger) NewItemf(level Level, format string, args ...any) *Item {
 	return l.newSelfItem(level, 1, fmt.Sprintf(format, args...))
 }
 
-// Genera un nuovo item di livello errore.
+// Genera un nuovo item di livello errore, arricchito dalle informazioni dell'errore (vedi Item.SetError()).
 // Eredita una copia del payload dal logger che può essere ulteriormente customizzata.
 func (l *Logger) NewErrorItem(err error) *Item {
-	return l.newSelfItem(ErrorLevel, 1, err.Error())
+	item := l.newSelfItem(ErrorLevel, 1, err.Error())
+	item.SetError(err)
+
+	return item
 }
 
 // Genera un nuovo item di livello errore.
@@ -110,20 +170,71 @@ func (l *Logger) SetPayload(key string, value any) {
 	l.muPayload.Lock()
 	defer l.muPayload.Unlock()
 
-	if l.payload == nil {
-		l.payload = make(map[string]any)
+	payload := make(map[string]any, len(l.payload)+1)
+	for k, v := range l.payload {
+		payload[k] = v
+	}
+	payload[key] = value
+
+	l.payload = payload
+}
+
+// Ritorna un logger derivato con gli stessi writer, prefisso e regole di livello,
+// e con il payload arricchito dalle coppie chiave, valore specificate
+// (es. l.With("user", id, "method", "GET")).
+// Le chiavi non stringa vengono convertite con fmt.Sprint, un valore senza chiave
+// viene riportato con la chiave "!BADKEY". Il logger originale non viene modificato.
+func (l *Logger) With(keysAndValues ...any) *Logger {
+	child := newAliasLogger(l, l.prefix)
+
+	if len(keysAndValues) == 0 {
+		return child
+	}
+
+	payload := make(map[string]any, len(child.payload)+(len(keysAndValues)+1)/2)
+	for k, v := range child.payload {
+		payload[k] = v
+	}
+
+	for i := 0; i < len(keysAndValues); i += 2 {
+		if i+1 == len(keysAndValues) {
+			payload["!BADKEY"] = keysAndValues[i]
+			break
+		}
+
+		key, ok := keysAndValues[i].(string)
+		if !ok {
+			key = fmt.Sprint(keysAndValues[i])
+		}
+
+		payload[key] = keysAndValues[i+1]
 	}
 
-	l.payload[key] = value
+	child.payload = payload
+
+	return child
+}
+
+// Ritorna un logger derivato con gli stessi writer, payload e regole di livello,
+// e con il prefisso annidato in quello corrente (es. "http" -> "http/users").
+// Il logger originale non viene modificato.
+func (l *Logger) WithPrefix(prefix string) *Logger {
+	if l.prefix != "" {
+		prefix = l.prefix + "/" + prefix
+	}
+
+	return newAliasLogger(l, prefix)
 }
 
 // Logga un item precedentemente generato.
 func (l *Logger) LogItem(item *Item) {
-	if !globalDispatcher.CanDispatch(item.Level) {
+	d := l.getDispatcher()
+
+	if !l.canDispatch(d, item.Level) {
 		return
 	}
 
-	globalDispatcher.Dispatch(item)
+	d.Dispatch(item)
 }
 
 // Imposta una funzione di inizializzazione per ogni item allocato dal logger.
@@ -131,6 +242,46 @@ func (l *Logger) SetInitItemFunc(f InitItemF) {
 	l.initItemF = f
 }
 
+// Ritorna il sistema del logger, o quello di default se il logger non ne ha uno proprio.
+func (l *Logger) getSystem() *System {
+	if l.system != nil {
+		return l.system
+	}
+
+	return defaultSystem.Load()
+}
+
+// Ritorna il dispatcher del sistema del logger.
+func (l *Logger) getDispatcher() *dispatcher {
+	return l.getSystem().dispatcher
+}
+
+// Ritorna il nome con cui il logger è stato registrato con GetLogger(),
+// o una stringa vuota se il logger non è registrato.
+func (l *Logger) Name() string {
+	return l.name
+}
+
+// Ritorna true se il livello è loggabile: se al logger si applica una regola
+// di livello (vedi SetLoggersLevels) questa prevale sui mute del dispatcher.
+func (l *Logger) canDispatch(d *dispatcher, level Level) bool {
+	if l.maxLevel != nil {
+		if max := l.maxLevel.Load(); max > 0 {
+			return !Level(max-1).MoreSevere(level) && d.CanDispatchMuted(level)
+		}
+	}
+
+	return d.CanDispatch(level)
+}
+
+// Ritorna il payload di default, da non modificare.
+func (l *Logger) getPayload() map[string]any {
+	l.muPayload.RLock()
+	defer l.muPayload.RUnlock()
+
+	return l.payload
+}
+
 // Ritorna una copia del payload di default.
 func (l *Logger) getPayloadCopy() map[string]any {
 	l.muPayload.RLock()
@@ -153,7 +304,7 @@ func (l *Logger) getPayloadCopy() map[string]any {
 // Eredita una copia del payload dal logger che può essere ulteriormente customizzata
 // ed eventualmente inizializza l'item con la funzione custom.
 func (l *Logger) newSelfItem(level Level, stackCallsToSkip int, msg string) *Item {
-	item := newItem(level, l.prefix, msg, l.stackCallsToSkip+stackCallsToSkip+1)
+	item := newItem(l.getSystem(), level, l.prefix, msg, stackCallsToSkip+1)
 
 	// Assegna una copia del payload del logger se ne è provvisto.
 	item.Payload = l.getPayloadCopy()
@@ -165,25 +316,80 @@ func (l *Logger) newSelfItem(level Level, stackCallsToSkip int, msg string) *Ite
 	return item
 }
 
-// Logga in uno specifico livello - entry point per tutti gli helper che loggano; thread safe.
+// Logga in uno specifico livello; thread safe.
 // Non fa nulla se il livello è mutato.
 func (l *Logger) log(level Level, args ...any) {
-	if !globalDispatcher.CanDispatch(level) {
+	l.logDepth(nil, level, 2, args...)
+}
+
+// Logga in uno specifico livello formattando il messaggio solo se il livello è loggabile; thread safe.
+func (l *Logger) logf(level Level, format string, args ...any) {
+	l.logfDepth(nil, level, 2, format, args...)
+}
+
+// Logga in uno specifico livello - entry point per tutti gli helper che loggano; thread safe.
+// Non fa nulla se il livello è mutato.
+//   - ctx: contesto da cui estrarre il payload (vedi ContextWithPayload e RegisterContextKey), può essere nil.
+//   - depth: numero di chiamate tra l'applicativo e logDepth(), da escludere dallo stacktrace.
+func (l *Logger) logDepth(ctx context.Context, level Level, depth int, args ...any) {
+	d := l.getDispatcher()
+
+	if !l.canDispatch(d, level) {
 		return
 	}
 
-	item := newItem(level, l.prefix, fmt.Sprint(args...), l.stackCallsToSkip+2)
+	l.dispatchNew(ctx, d, newPooledItem(l.getSystem(), level, l.prefix, sprint(args), depth+1))
+}
 
-	// Assegna direttamente il puntatore al payload,
-	// dal momento che l'item viene generato e immediatamente loggato
-	// senza essere ulteriormente manipolato.
-	l.muPayload.RLock()
-	item.Payload = l.payload
-	l.muPayload.RUnlock()
+// Come logDepth(), con il messaggio formattato da fmt.Sprintf.
+func (l *Logger) logfDepth(ctx context.Context, level Level, depth int, format string, args ...any) {
+	d := l.getDispatcher()
+
+	if !l.canDispatch(d, level) {
+		return
+	}
+
+	l.dispatchNew(ctx, d, newPooledItem(l.getSystem(), level, l.prefix, fmt.Sprintf(format, args...), depth+1))
+}
+
+// Come logDepth(), con il messaggio generato da f.
+func (l *Logger) logFuncDepth(ctx context.Context, level Level, depth int, f func() string) {
+	d := l.getDispatcher()
+
+	if !l.canDispatch(d, level) {
+		return
+	}
+
+	l.dispatchNew(ctx, d, newPooledItem(l.getSystem(), level, l.prefix, f(), depth+1))
+}
+
+// Completa un item appena generato dal pool con payload e contesto, lo invia al dispatcher e lo rilascia.
+func (l *Logger) dispatchNew(ctx context.Context, d *dispatcher, item *Item) {
+	// Assegna direttamente il puntatore al payload, dal momento che l'item viene generato
+	// e immediatamente loggato: viene copiato solo se modificato da initItemF (vedi Item.SetPayload()).
+	item.Payload = l.getPayload()
+	item.sharedPayload = true
+
+	if ctx != nil {
+		item.Payload = mergeContextPayload(ctx, item.Payload)
+	}
 
 	if l.initItemF != nil {
 		l.initItemF(item)
 	}
 
-	globalDispatcher.Dispatch(item)
+	d.Dispatch(item)
+
+	item.Release()
+}
+
+// Come fmt.Sprint(), senza allocazioni nel caso di singola stringa.
+func sprint(args []any) string {
+	if len(args) == 1 {
+		if s, ok := args[0].(string); ok {
+			return s
+		}
+	}
+
+	return fmt.Sprint(args...)
 }
@@ -2,22 +2,51 @@ package logs
 
 // Funzioni e variabili generali.
 
+import "time"
+
 // Interfaccia del writer usata dal logger.
 type Writer interface {
+	// Identificativo univoco, generato casualmente.
 	ID() string
+	// Nome descrittivo, eventualmente vuoto.
+	Name() string
 
+	// Riceve un item da loggare. Se il writer implementa PoolSafeWriter l'item
+	// può essere riciclato dopo il ritorno: se utilizzato successivamente (es. accodato)
+	// va prima acquisito con Item.Retain().
 	Write(*Item)
 
 	Start() error
+	// Può essere invocata anche su un writer mai avviato o già stoppato
+	// (es. se fallisce l'applicazione di una configurazione): deve quindi essere idempotente.
 	Stop()
+	// Imposta il canale di feedback; nil all'arresto del dispatcher, prima della chiusura del canale:
+	// al ritorno il writer non deve più inviarvi item.
 	SetFeedbackChan(chan *Item)
 }
 
+// Interfaccia opzionale dei writer che rispettano il contratto di riciclo degli item
+// (vedi Item.Retain()), implementata dai writer del package writers.
+// Gli item inviati anche a un solo writer che non la implementa non vengono riciclati,
+// e possono quindi essere conservati liberamente.
+type PoolSafeWriter interface {
+	PoolSafe()
+}
+
+// Interfaccia opzionale dei writer asincroni, usata da Flush() per attendere
+// la consegna degli item accodati (implementata da writers.Writer).
+type Flusher interface {
+	// Attende l'elaborazione degli item accodati al più per timeout;
+	// ritorna false se il timeout è scaduto prima.
+	Flush(timeout time.Duration) bool
+}
+
 // Exit Code generato da Fatal() e Fatalf().
 var FatalExitCode = 1
 
 // Funzione di inizializzazione item appena dopo la sua allocazione,
 // permette di settare ulteriormente in modo custom specifiche proprietà.
+// Il payload va modificato tramite Item.SetPayload(), dal momento che può essere condiviso con il logger.
 type InitItemF func(*Item)
 
 // Alloca un nuovo logger dotato di prefisso e di differente payload,
@@ -25,15 +54,25 @@ type InitItemF func(*Item)
 // Eredita una copia del payload dal logger di default.
 // - prefix: prefisso di default che comparirà nelle relative loggate.
 func NewLogger(prefix string) *Logger {
-	return newAliasLogger(defaultLogger, prefix)
+	return newAliasLogger(defaultLogger(), prefix)
+}
+
+// Alloca un nuovo logger appartenente a un proprio sistema indipendente da quello di default
+// (vedi NewSystem()), con propri writer, livelli mutati, stacktrace e ciclo di vita (Start/Stop).
+// I livelli debug e trace sono mutati di default, come per il logger di default.
+// - prefix: prefisso di default che comparirà nelle relative loggate.
+// - defaultWriter: writer di default per tutti i livelli
+// (riceve le eventuali loggate di errore o di feedback da parte degli altri writer).
+func NewIsolatedLogger(prefix string, defaultWriter Writer) *Logger {
+	return NewSystem(defaultWriter).NewLogger(prefix)
 }
 
 // Setta un valore del payload di default del logger di default.
 func SetPayload(name string, value any) {
-	defaultLogger.SetPayload(name, value)
+	defaultLogger().SetPayload(name, value)
 }
 
 // Imposta una funzione di inizializzazione per ogni item allocato dal logger di default.
 func SetInitItemFunc(f InitItemF) {
-	defaultLogger.initItemF = f
+	defaultLogger().initItemF = f
 }
@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/mitchellh/panicwrap"
+	"github.com/modulo-srl/sparalog/env"
 )
 
 // StartPanicWatcher starts a supervisor that monitors panics in all goroutines.
@@ -37,20 +38,38 @@ func StartPanicWatcher() {
 //   - output: contiene l'intero output (compreso di stacktrace)
 //     del panic del processo figlio.
 func panicHandler(output string) {
-	var st string
-
 	time.Sleep(time.Second * 3)
 
-	// Aggiorna lo stacktrace.
-	i := strings.Index(output, "\n\n")
-	if i >= 0 {
-		st = "STACKTRACE (by panic watcher): " + strings.TrimSpace(output[i+2:])
-		output = output[:i]
+	defaultLogger().LogItem(newPanicItem(output))
+
+	DefaultSystem().Stop()
+}
+
+// Genera la loggata fatale dall'output del panic, con lo stacktrace della goroutine in panic.
+// Le tracce delle altre goroutine vengono mantenute testualmente nel payload "goroutines",
+// così come l'intera traccia nel payload "stacktrace" se non interpretabile.
+func newPanicItem(output string) *Item {
+	msg, trace, _ := strings.Cut(output, "\n\n")
+
+	item := NewItem(FatalLevel, msg)
+
+	trace = strings.TrimSpace(trace)
+	if trace == "" {
+		return item
 	}
 
-	item := NewItem(FatalLevel, output)
-	item.StackTrace = st
-	defaultLogger.LogItem(item)
+	first, others, _ := strings.Cut(trace, "\n\n")
+
+	item.Stack = env.ParseStack(first)
+	if item.Stack == nil {
+		item.SetPayload("stacktrace", trace)
+		return item
+	}
+
+	others = strings.TrimSpace(others)
+	if others != "" {
+		item.SetPayload("goroutines", others)
+	}
 
-	globalDispatcher.Stop()
+	return item
 }
@@ -0,0 +1,161 @@
+package logs
+
+// Registro dei logger nominativi e regole di livello per nome.
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+type registry struct {
+	// Logger da cui derivano i logger registrati.
+	parent *Logger
+
+	mu      sync.Mutex
+	loggers map[string]*Logger
+	rules   []levelRule
+}
+
+// Regola di livello: i logger il cui nome corrisponde al pattern
+// loggano fino al livello specificato, indipendentemente dai mute.
+type levelRule struct {
+	pattern string
+	level   Level
+}
+
+func newRegistry(parent *Logger) *registry {
+	return &registry{
+		parent:  parent,
+		loggers: make(map[string]*Logger),
+	}
+}
+
+// Ritorna il logger registrato nel sistema di default con il nome specifico, allocandolo se non esiste.
+// Il logger deriva dal logger di default e ha il nome come prefisso.
+// I nomi sono gerarchici con "/" come separatore (es. "db/pool"),
+// in modo da poter essere selezionati con SetLoggersLevels().
+func GetLogger(name string) *Logger {
+	return DefaultSystem().GetLogger(name)
+}
+
+// Ritorna tutti i logger registrati nel sistema di default, ordinati per nome.
+func Loggers() []*Logger {
+	return DefaultSystem().Loggers()
+}
+
+// Imposta le regole di livello dei logger registrati nel sistema di default, nel formato
+//
+//	pattern=livello,pattern=livello,...
+//
+// ad esempio "db/*=debug,http=warning".
+// I pattern seguono la sintassi di path.Match e, in caso di più regole
+// corrispondenti allo stesso logger, prevale l'ultima.
+// Un logger a cui si applica una regola logga tutti i livelli gravi almeno quanto quello indicato
+// (vedi LevelInfo.Rank), anche se mutati globalmente, e nessuno dei livelli meno gravi.
+// Una stringa vuota rimuove tutte le regole.
+func SetLoggersLevels(spec string) error {
+	return DefaultSystem().SetLoggersLevels(spec)
+}
+
+// Verifica le regole di livello nel formato di SetLoggersLevels(), senza applicarle.
+func ValidateLoggersLevels(spec string) error {
+	_, err := parseLevelRules(spec)
+	return err
+}
+
+func (r *registry) get(name string) *Logger {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	l, ok := r.loggers[name]
+	if ok {
+		return l
+	}
+
+	l = newAliasLogger(r.parent, name)
+	l.name = name
+	l.maxLevel = &atomic.Int32{}
+	r.applyRules(l)
+
+	r.loggers[name] = l
+
+	return l
+}
+
+func (r *registry) list() []*Logger {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	ll := make([]*Logger, 0, len(r.loggers))
+	for _, l := range r.loggers {
+		ll = append(ll, l)
+	}
+
+	sort.Slice(ll, func(i, j int) bool {
+		return ll[i].name < ll[j].name
+	})
+
+	return ll
+}
+
+func (r *registry) setRules(rules []levelRule) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.rules = rules
+
+	for _, l := range r.loggers {
+		r.applyRules(l)
+	}
+}
+
+// Applica al logger l'ultima regola corrispondente al suo nome.
+// Va invocata con r.mu acquisito.
+func (r *registry) applyRules(l *Logger) {
+	var max int32
+
+	for _, rule := range r.rules {
+		if ok, _ := path.Match(rule.pattern, l.name); ok {
+			max = int32(rule.level) + 1
+		}
+	}
+
+	l.maxLevel.Store(max)
+}
+
+func parseLevelRules(spec string) ([]levelRule, error) {
+	var rules []levelRule
+
+	for _, s := range strings.Split(spec, ",") {
+		s = strings.TrimSpace(s)
+		if s == "" {
+			continue
+		}
+
+		pattern, levelName, ok := strings.Cut(s, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid level rule %q", s)
+		}
+
+		pattern = strings.TrimSpace(pattern)
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid level rule %q: %w", s, err)
+		}
+
+		level, err := ParseLevel(strings.TrimSpace(levelName))
+		if err != nil {
+			return nil, fmt.Errorf("invalid level rule %q: %w", s, err)
+		}
+
+		rules = append(rules, levelRule{
+			pattern: pattern,
+			level:   level,
+		})
+	}
+
+	return rules, nil
+}
@@ -0,0 +1,225 @@
+package logs
+
+// Handler log/slog che converte i record in item di sparalog.
+
+import (
+	"context"
+	"log/slog"
+	"runtime"
+
+	"github.com/modulo-srl/sparalog/env"
+)
+
+// Handler slog.Handler che invia i record a un logger di sparalog.
+// Gli attributi diventano valori del payload; quelli appartenenti a gruppi
+// vengono nominati con il percorso completo separato da punti ("group.key").
+type SlogHandler struct {
+	logger *Logger
+
+	// Attributi aggiunti con WithAttrs(), già risolti in chiavi di payload.
+	attrs map[string]any
+
+	// Prefisso delle chiavi dei gruppi aperti con WithGroup().
+	group string
+}
+
+// Ritorna un handler slog che invia i record al logger specificato
+// (al logger di default se nil).
+//
+//	slog.SetDefault(slog.New(logs.NewSlogHandler(nil)))
+func NewSlogHandler(l *Logger) *SlogHandler {
+	if l == nil {
+		l = defaultLogger()
+	}
+
+	return &SlogHandler{
+		logger: l,
+	}
+}
+
+// Converte un livello slog nel livello di sparalog corrispondente:
+// i livelli inferiori a slog.LevelDebug diventano TraceLevel,
+// quelli superiori o uguali a slog.LevelError+2 diventano CriticalLevel.
+func LevelFromSlog(level slog.Level) Level {
+	switch {
+	case level < slog.LevelDebug:
+		return TraceLevel
+	case level < slog.LevelInfo:
+		return DebugLevel
+	case level < slog.LevelInfo+2:
+		return InfoLevel
+	case level < slog.LevelWarn:
+		return NoticeLevel
+	case level < slog.LevelError:
+		return WarningLevel
+	case level < slog.LevelError+2:
+		return ErrorLevel
+	}
+
+	return CriticalLevel
+}
+
+// Converte un livello di sparalog nel livello slog corrispondente;
+// FatalLevel diventa slog.LevelError+4, gli altri livelli vengono convertiti
+// in base alla severità syslog (vedi LevelInfo.SyslogSeverity).
+func SlogLevel(level Level) slog.Level {
+	switch level {
+	case FatalLevel:
+		return slog.LevelError + 4
+	case TraceLevel:
+		return slog.LevelDebug - 4
+	}
+
+	switch level.Info().SyslogSeverity {
+	case 0, 1, 2:
+		return slog.LevelError + 2
+	case 3:
+		return slog.LevelError
+	case 4:
+		return slog.LevelWarn
+	case 5:
+		return slog.LevelInfo + 2
+	case 6:
+		return slog.LevelInfo
+	}
+
+	return slog.LevelDebug
+}
+
+func (h *SlogHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.logger.canDispatch(h.logger.getDispatcher(), LevelFromSlog(level))
+}
+
+func (h *SlogHandler) Handle(ctx context.Context, r slog.Record) error {
+	l := h.logger
+	level := LevelFromSlog(r.Level)
+
+	d := l.getDispatcher()
+	if !l.canDispatch(d, level) {
+		return nil
+	}
+
+	item := newBareItem(level, l.prefix, r.Message)
+
+	if !r.Time.IsZero() {
+		item.Ts = r.Time
+	}
+
+	s := l.getSystem()
+
+	if s.levelStackTrace(level) {
+		item.Stack = slogStack(r.PC)
+	}
+
+	if s.levelCaller(level) && r.PC != 0 {
+		frame, _ := runtime.CallersFrames([]uintptr{r.PC}).Next()
+		item.Caller = &env.Frame{
+			Function: frame.Function,
+			File:     frame.File,
+			Line:     frame.Line,
+		}
+	}
+
+	item.Payload = l.getPayloadCopy()
+
+	for k, v := range h.attrs {
+		item.SetPayload(k, v)
+	}
+
+	r.Attrs(func(a slog.Attr) bool {
+		addSlogAttr(item.SetPayload, h.group, a)
+		return true
+	})
+
+	if ctx != nil {
+		item.SetContextPayload(ctx)
+	}
+
+	if l.initItemF != nil {
+		l.initItemF(item)
+	}
+
+	d.Dispatch(item)
+
+	return nil
+}
+
+func (h *SlogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+
+	h2 := *h
+	h2.attrs = make(map[string]any, len(h.attrs)+len(attrs))
+
+	for k, v := range h.attrs {
+		h2.attrs[k] = v
+	}
+
+	for _, a := range attrs {
+		addSlogAttr(func(k string, v any) {
+			h2.attrs[k] = v
+		}, h.group, a)
+	}
+
+	return &h2
+}
+
+func (h *SlogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+
+	h2 := *h
+	h2.group = h.group + name + "."
+
+	return &h2
+}
+
+// Aggiunge un attributo tramite set(), espandendo ricorsivamente i gruppi.
+func addSlogAttr(set func(string, any), group string, a slog.Attr) {
+	a.Value = a.Value.Resolve()
+
+	if a.Equal(slog.Attr{}) {
+		return
+	}
+
+	if a.Value.Kind() == slog.KindGroup {
+		attrs := a.Value.Group()
+		if len(attrs) == 0 {
+			return
+		}
+
+		// I gruppi senza nome vengono espansi nel gruppo corrente.
+		if a.Key != "" {
+			group += a.Key + "."
+		}
+
+		for _, ga := range attrs {
+			addSlogAttr(set, group, ga)
+		}
+		return
+	}
+
+	set(group+a.Key, a.Value.Any())
+}
+
+// Ritorna lo stacktrace a partire dalla chiamata che ha generato il record.
+func slogStack(pc uintptr) *env.Stack {
+	st := env.CaptureStack(2)
+
+	if pc == 0 {
+		return st
+	}
+
+	caller, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+
+	for i, f := range st.Frames {
+		if f.Function == caller.Function && f.Line == caller.Line {
+			st.Frames = st.Frames[i:]
+			break
+		}
+	}
+
+	return st
+}
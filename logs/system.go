@@ -0,0 +1,241 @@
+package logs
+
+// Sistema di logging indipendente.
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// Sistema di logging: possiede un proprio dispatcher (writer, livelli mutati, hook fatali),
+// una propria configurazione di stacktrace e posizione del chiamante, un logger di default
+// e un registro dei logger con nome.
+// Più sistemi possono coesistere nello stesso processo (es. test paralleli o plugin);
+// le funzioni del package operano sul sistema di default, reinizializzato da InitDefaultLogger().
+// Restano invece globali i livelli registrati con RegisterLevel(), le chiavi di contesto,
+// il formato dei timestamp e l'orologio.
+type System struct {
+	dispatcher *dispatcher
+
+	// Logger di default del sistema.
+	logger *Logger
+
+	// Registro dei logger con nome.
+	registry *registry
+
+	// Per quali livelli lo stacktrace è abilitato.
+	levelsStackTrace atomic.Pointer[[]bool]
+
+	// Per quali livelli la posizione del chiamante è abilitata.
+	levelsCaller atomic.Pointer[[]bool]
+}
+
+// Alloca un nuovo sistema di logging, da avviare con Start().
+// Come per il sistema di default, lo stacktrace è abilitato per i livelli fatal, critical ed error,
+// e i livelli debug e trace sono mutati.
+// - defaultWriter: writer di default per tutti i livelli
+// (riceve le eventuali loggate di errore o di feedback da parte degli altri writer).
+func NewSystem(defaultWriter Writer) *System {
+	s := System{
+		dispatcher: newDispatcher(defaultWriter),
+	}
+
+	s.logger = &Logger{
+		system: &s,
+	}
+
+	s.registry = newRegistry(s.logger)
+
+	s.EnableLevelsStackTrace([]Level{FatalLevel, CriticalLevel, ErrorLevel})
+	s.EnableLevelsCaller(nil)
+
+	s.dispatcher.Mute(DebugLevel, true)
+	s.dispatcher.Mute(TraceLevel, true)
+
+	return &s
+}
+
+// Ritorna il logger di default del sistema.
+func (s *System) Logger() *Logger {
+	return s.logger
+}
+
+// Alloca un nuovo logger del sistema dotato di prefisso,
+// che eredita il payload dal logger di default del sistema.
+func (s *System) NewLogger(prefix string) *Logger {
+	return newAliasLogger(s.logger, prefix)
+}
+
+// Ritorna il logger registrato nel sistema con il nome specifico, allocandolo se non esiste (vedi GetLogger()).
+func (s *System) GetLogger(name string) *Logger {
+	return s.registry.get(name)
+}
+
+// Ritorna tutti i logger registrati nel sistema, ordinati per nome.
+func (s *System) Loggers() []*Logger {
+	return s.registry.list()
+}
+
+// Imposta le regole di livello dei logger registrati nel sistema (vedi SetLoggersLevels()).
+func (s *System) SetLoggersLevels(spec string) error {
+	rules, err := parseLevelRules(spec)
+	if err != nil {
+		return err
+	}
+
+	s.registry.setRules(rules)
+
+	return nil
+}
+
+// Attiva lo stacktrace per specifici livelli, disattivandolo per gli altri.
+// Thread safe: invocabile anche mentre il sistema sta loggando (es. ricaricando la configurazione).
+func (s *System) EnableLevelsStackTrace(levels []Level) {
+	s.levelsStackTrace.Store(levelsFlags(levels))
+}
+
+// Attiva la posizione del chiamante (file, riga e funzione) per specifici livelli:
+// molto più economica dello stacktrace, dal momento che viene risolto un solo frame.
+// Thread safe, come EnableLevelsStackTrace().
+func (s *System) EnableLevelsCaller(levels []Level) {
+	s.levelsCaller.Store(levelsFlags(levels))
+}
+
+// Ritorna true se lo stacktrace è abilitato per il livello.
+func (s *System) levelStackTrace(level Level) bool {
+	return levelFlag(s.levelsStackTrace.Load(), level)
+}
+
+// Ritorna true se la posizione del chiamante è abilitata per il livello.
+func (s *System) levelCaller(level Level) bool {
+	return levelFlag(s.levelsCaller.Load(), level)
+}
+
+// Ritorna i flag indicizzati per livello, attivi per i livelli specificati.
+func levelsFlags(levels []Level) *[]bool {
+	flags := make([]bool, levelsCount())
+
+	for _, level := range levels {
+		if level >= 0 && int(level) < len(flags) {
+			flags[level] = true
+		}
+	}
+
+	return &flags
+}
+
+// Ritorna il flag del livello, false se non impostato.
+func levelFlag(flags *[]bool, level Level) bool {
+	return flags != nil && level >= 0 && int(level) < len(*flags) && (*flags)[level]
+}
+
+// Avvia tutti i writer del sistema.
+// I writer associati successivamente vengono avviati automaticamente.
+func (s *System) Start() error {
+	return s.dispatcher.Start()
+}
+
+// Termina i writer del sistema attendendo gentilmente il termine dei writer asincroni.
+func (s *System) Stop() {
+	s.dispatcher.Stop()
+}
+
+// Attende che i writer asincroni del sistema abbiano consegnato gli item accodati,
+// al più per timeout (vedi Flush()).
+func (s *System) Flush(timeout time.Duration) error {
+	return s.dispatcher.Flush(timeout)
+}
+
+// Muta o smuta un livello.
+func (s *System) Mute(level Level, state bool) {
+	s.dispatcher.Mute(level, state)
+}
+
+// Disassocia tutti i writer e reimposta un writer di default (vedi ResetWriters()).
+func (s *System) ResetWriters(defaultW Writer) error {
+	return s.dispatcher.ResetWriters(defaultW)
+}
+
+// Disassocia tutti i writer per un certo livello e ne reimposta un writer di default.
+func (s *System) ResetLevelWriters(level Level, defaultW Writer) error {
+	return s.dispatcher.ResetLevelWriters(level, defaultW)
+}
+
+// Disassocia tutti i writer per un set di livelli e ne reimposta un writer di default.
+func (s *System) ResetLevelsWriters(levels []Level, defaultW Writer) error {
+	return s.dispatcher.ResetLevelsWriters(levels, defaultW)
+}
+
+// Associa un writer a tutti i livelli.
+func (s *System) AddWriter(w Writer) error {
+	return s.dispatcher.AddWriter(w)
+}
+
+// Associa un writer a uno specifico livello.
+func (s *System) AddLevelWriter(level Level, w Writer) error {
+	return s.dispatcher.AddLevelWriter(level, w)
+}
+
+// Associa un writer a un set di livelli.
+func (s *System) AddLevelsWriter(levels []Level, w Writer) error {
+	return s.dispatcher.AddLevelsWriter(levels, w)
+}
+
+// Sostituisce atomicamente tutte le associazioni dei writer (vedi ReplaceWriters()).
+func (s *System) ReplaceWriters(defaultW Writer, routes []WriterRoute) error {
+	return s.dispatcher.ReplaceWriters(defaultW, routes)
+}
+
+// Disassocia un writer da tutti i livelli, stoppandolo.
+func (s *System) RemoveWriter(id string) error {
+	return s.dispatcher.RemoveWriter(id)
+}
+
+// Disassocia un writer da uno specifico livello, stoppandolo se non più associato ad alcun livello.
+func (s *System) RemoveLevelWriter(level Level, id string) error {
+	return s.dispatcher.RemoveLevelWriter(level, id)
+}
+
+// Ritorna le informazioni su tutti i writer associati ad almeno un livello.
+func (s *System) Writers() []WriterInfo {
+	return s.dispatcher.Writers()
+}
+
+// Imposta la finestra di soppressione dei duplicati (vedi SetDedupWindow()).
+func (s *System) SetDedupWindow(window time.Duration) {
+	s.dispatcher.throttle.SetDedupWindow(window)
+}
+
+// Imposta un rate limit per un livello (vedi SetRateLimit()).
+func (s *System) SetRateLimit(level Level, perSecond float64, burst int) {
+	s.dispatcher.throttle.SetRateLimit(level, perSecond, burst)
+}
+
+// Registra un hook invocato a ogni loggata fatale del sistema (vedi OnFatal()).
+func (s *System) OnFatal(hook FatalHook) {
+	s.dispatcher.OnFatal(hook)
+}
+
+// Sostituisce la funzione di terminazione invocata dopo una loggata fatale del sistema (nil = os.Exit).
+func (s *System) SetExitFunc(f ExitFunc) {
+	s.dispatcher.SetExitFunc(f)
+}
+
+// Imposta il tempo massimo concesso complessivamente agli hook OnFatal del sistema
+// (vedi SetFatalHooksTimeout()).
+func (s *System) SetFatalHooksTimeout(timeout time.Duration) {
+	s.dispatcher.SetFatalHooksTimeout(timeout)
+}
+
+// Sistema di default, su cui operano le funzioni del package.
+var defaultSystem atomic.Pointer[System]
+
+// Ritorna il sistema di default, su cui operano le funzioni del package.
+func DefaultSystem() *System {
+	return defaultSystem.Load()
+}
+
+// Ritorna il logger del sistema di default.
+func defaultLogger() *Logger {
+	return defaultSystem.Load().logger
+}
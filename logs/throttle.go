@@ -0,0 +1,259 @@
+package logs
+
+// Stadio opzionale del dispatcher per la soppressione dei duplicati e il rate limiting.
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/modulo-srl/sparalog/env"
+)
+
+// Intervallo con cui vengono riportati gli item scartati dal rate limiting.
+var RateLimitReportInterval = 10 * time.Second
+
+// Prefisso degli item di riepilogo generati dal dispatcher.
+const throttlePrefix = "(log dispatcher)"
+
+type throttle struct {
+	// Attivo se almeno una tra soppressione duplicati e rate limiting è abilitata;
+	// evita il lock nel caso (di default) in cui lo stadio non è usato.
+	active atomic.Bool
+
+	mu sync.Mutex
+
+	// Finestra di soppressione dei duplicati (0 = disabilitata).
+	window  time.Duration
+	entries map[dedupKey]*dedupEntry
+
+	limits  map[Level]*tokenBucket
+	dropped map[Level]*droppedEntry
+
+	// Invia gli item di riepilogo ai writer, salvo che il livello sia mutato
+	// e ignoreMute non sia impostato.
+	emit func(item *Item, ignoreMute bool)
+
+	// Ritorna true se il livello è mutato.
+	isMuted func(Level) bool
+}
+
+type dedupKey struct {
+	level           Level
+	prefix, message string
+}
+
+type dedupEntry struct {
+	// Item identici soppressi dopo il primo.
+	count int
+
+	// Copie di payload e posizione del chiamante del primo item, riportate nel riepilogo.
+	payload map[string]any
+	caller  *env.Frame
+
+	// Il livello era mutato al primo item, passato grazie alle regole del logger:
+	// il riepilogo ignora a sua volta il mute.
+	ignoreMute bool
+}
+
+type droppedEntry struct {
+	// Item scartati dal rate limiting.
+	count int
+
+	// Come in dedupEntry, relativo al primo item scartato.
+	ignoreMute bool
+}
+
+type tokenBucket struct {
+	rate   float64 // token al secondo
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newThrottle(emit func(item *Item, ignoreMute bool), isMuted func(Level) bool) *throttle {
+	return &throttle{
+		entries: make(map[dedupKey]*dedupEntry),
+		limits:  make(map[Level]*tokenBucket),
+		dropped: make(map[Level]*droppedEntry),
+		emit:    emit,
+		isMuted: isMuted,
+	}
+}
+
+// Imposta la finestra di soppressione dei duplicati (0 = disabilitata).
+func (t *throttle) SetDedupWindow(window time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.window = window
+	t.updateActive()
+}
+
+// Imposta il rate limit di un livello (perSecond <= 0 = disabilitato).
+func (t *throttle) SetRateLimit(level Level, perSecond float64, burst int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if perSecond <= 0 {
+		delete(t.limits, level)
+		t.updateActive()
+		return
+	}
+
+	if burst < 1 {
+		burst = 1
+	}
+
+	t.limits[level] = &tokenBucket{
+		rate:   perSecond,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+	t.updateActive()
+}
+
+// Ritorna true se l'item va inviato ai writer,
+// false se è stato soppresso come duplicato o per rate limiting.
+func (t *throttle) Allow(item *Item) bool {
+	if !t.active.Load() || item.Level == FatalLevel {
+		return true
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	key := dedupKey{item.Level, item.Prefix, item.Message}
+
+	if t.window > 0 {
+		if e, ok := t.entries[key]; ok {
+			e.count++
+			return false
+		}
+	}
+
+	if b := t.limits[item.Level]; b != nil && !b.take(time.Now()) {
+		e := t.dropped[item.Level]
+		if e == nil {
+			e = &droppedEntry{ignoreMute: t.isMuted(item.Level)}
+			t.dropped[item.Level] = e
+
+			level := item.Level
+			time.AfterFunc(RateLimitReportInterval, func() {
+				t.reportDropped(level)
+			})
+		}
+		e.count++
+		return false
+	}
+
+	if t.window > 0 {
+		e := &dedupEntry{
+			ignoreMute: t.isMuted(item.Level),
+		}
+
+		// L'item può essere riciclato dopo l'invio: ne vengono conservate delle copie.
+		if len(item.Payload) > 0 {
+			e.payload = make(map[string]any, len(item.Payload)+1)
+			for k, v := range item.Payload {
+				e.payload[k] = v
+			}
+		}
+		if item.Caller != nil {
+			caller := *item.Caller
+			e.caller = &caller
+		}
+
+		t.entries[key] = e
+		time.AfterFunc(t.window, func() {
+			t.closeWindow(key)
+		})
+	}
+
+	return true
+}
+
+// Riporta tutti i conteggi pendenti; invocata all'arresto del dispatcher.
+func (t *throttle) Flush() {
+	t.mu.Lock()
+	keys := make([]dedupKey, 0, len(t.entries))
+	for key := range t.entries {
+		keys = append(keys, key)
+	}
+	t.mu.Unlock()
+
+	for _, key := range keys {
+		t.closeWindow(key)
+	}
+
+	t.mu.Lock()
+	levels := make([]Level, 0, len(t.dropped))
+	for level := range t.dropped {
+		levels = append(levels, level)
+	}
+	t.mu.Unlock()
+
+	for _, level := range levels {
+		t.reportDropped(level)
+	}
+}
+
+// Chiude la finestra di un item, riportando il numero di duplicati soppressi.
+func (t *throttle) closeWindow(key dedupKey) {
+	t.mu.Lock()
+	e, ok := t.entries[key]
+	delete(t.entries, key)
+	t.mu.Unlock()
+
+	if !ok || e.count == 0 {
+		return
+	}
+
+	item := newBareItem(key.level, key.prefix, fmt.Sprintf("%s (repeated %d times)", key.message, e.count))
+	item.Payload = e.payload
+	item.Caller = e.caller
+	item.SetPayload("repeated", e.count)
+
+	t.emit(item, e.ignoreMute)
+}
+
+// Riporta il numero di item di un livello scartati dal rate limiting.
+func (t *throttle) reportDropped(level Level) {
+	t.mu.Lock()
+	e := t.dropped[level]
+	delete(t.dropped, level)
+	t.mu.Unlock()
+
+	if e == nil {
+		return
+	}
+
+	item := newBareItem(level, throttlePrefix, fmt.Sprintf("%d %s items suppressed by rate limit", e.count, level))
+	item.SetPayload("suppressed", e.count)
+
+	t.emit(item, e.ignoreMute)
+}
+
+// Va invocata con t.mu acquisito.
+func (t *throttle) updateActive() {
+	t.active.Store(t.window > 0 || len(t.limits) > 0)
+}
+
+// Consuma un token se disponibile.
+func (b *tokenBucket) take(now time.Time) bool {
+	b.tokens += now.Sub(b.last).Seconds() * b.rate
+	if b.tokens > b.burst {
+		b.tokens = b.burst
+	}
+	b.last = now
+
+	if b.tokens < 1 {
+		return false
+	}
+
+	b.tokens--
+
+	return true
+}
@@ -0,0 +1,120 @@
+package logs
+
+// Formato dei timestamp e orologio degli item.
+
+import (
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+// Precisione dei timestamp renderizzati come Unix epoch.
+type EpochPrecision int
+
+const (
+	// Timestamp renderizzato secondo il layout (default).
+	EpochNone EpochPrecision = iota
+	EpochSeconds
+	EpochMillis
+	EpochMicros
+	EpochNanos
+)
+
+// Formato di rendering dei timestamp.
+type TimeFormat struct {
+	// Layout di time.Format() (vuoto = "2006-01-02 15:04:05.000").
+	Layout string
+
+	// Fuso orario (nil = UTC); usare time.Local per l'ora locale.
+	Location *time.Location
+
+	// Se impostata il timestamp viene renderizzato come Unix epoch intero
+	// con la precisione specificata, ignorando Layout e Location.
+	Epoch EpochPrecision
+}
+
+// Formato di default dei timestamp.
+var DefaultTimeFormat = TimeFormat{
+	Layout: "2006-01-02 15:04:05.000",
+}
+
+var globalTimeFormat atomic.Pointer[TimeFormat]
+
+// Imposta il formato globale dei timestamp, usato da Item.Timestamp() e dai formatter
+// che non ne hanno uno specifico; thread safe.
+// Vale per tutti i sistemi del processo e non viene reimpostato da InitDefaultLogger():
+// i test che lo modificano devono ripristinarlo (es. SetTimeFormat(DefaultTimeFormat)).
+func SetTimeFormat(tf TimeFormat) {
+	globalTimeFormat.Store(&tf)
+}
+
+// Ritorna il formato globale dei timestamp.
+func GetTimeFormat() TimeFormat {
+	return *getTimeFormat()
+}
+
+func getTimeFormat() *TimeFormat {
+	if tf := globalTimeFormat.Load(); tf != nil {
+		return tf
+	}
+
+	return &DefaultTimeFormat
+}
+
+// Appende a buf il timestamp renderizzato, senza allocazioni se buf ha capacità sufficiente.
+func (tf *TimeFormat) AppendFormat(buf []byte, ts time.Time) []byte {
+	switch tf.Epoch {
+	case EpochSeconds:
+		return strconv.AppendInt(buf, ts.Unix(), 10)
+	case EpochMillis:
+		return strconv.AppendInt(buf, ts.UnixMilli(), 10)
+	case EpochMicros:
+		return strconv.AppendInt(buf, ts.UnixMicro(), 10)
+	case EpochNanos:
+		return strconv.AppendInt(buf, ts.UnixNano(), 10)
+	}
+
+	loc := tf.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+
+	layout := tf.Layout
+	if layout == "" {
+		layout = DefaultTimeFormat.Layout
+	}
+
+	return ts.In(loc).AppendFormat(buf, layout)
+}
+
+// Ritorna il timestamp renderizzato.
+func (tf *TimeFormat) Format(ts time.Time) string {
+	return string(tf.AppendFormat(nil, ts))
+}
+
+// Orologio usato per il timestamp degli item.
+type Clock func() time.Time
+
+var globalClock atomic.Pointer[Clock]
+
+// Imposta l'orologio con cui viene generato il timestamp (Item.Ts) dei nuovi item,
+// es. per controllarlo nei test o riprodurre loggate registrate (nil = time.Now); thread safe.
+// Vale per tutti i sistemi del processo e non viene reimpostato da InitDefaultLogger():
+// i test che lo modificano devono ripristinarlo con SetClock(nil).
+func SetClock(clock Clock) {
+	if clock == nil {
+		globalClock.Store(nil)
+		return
+	}
+
+	globalClock.Store(&clock)
+}
+
+// Ritorna l'ora corrente secondo l'orologio impostato.
+func now() time.Time {
+	if clock := globalClock.Load(); clock != nil {
+		return (*clock)()
+	}
+
+	return time.Now()
+}
@@ -1,47 +1,222 @@
 package logs
 
 // Funzioni per gestire i writer.
+// Sono tutte thread safe e invocabili anche a logging in corso:
+// i writer associati dopo l'avvio vengono avviati automaticamente,
+// quelli non più associati ad alcun livello vengono stoppati gentilmente.
+// Se l'avvio automatico di un writer fallisce la modifica non viene applicata
+// e ne viene ritornato l'errore.
+
+import "time"
 
 // Disassocia tutti i writer e reimposta un writer di default
 // (il writer di default riceve le eventuali loggate di errore o di feedback da parte degli altri writer).
-// NON thread safe.
-func ResetWriters(defaultW Writer) {
-	globalDispatcher.ResetWriters(defaultW)
+func ResetWriters(defaultW Writer) error {
+	return DefaultSystem().ResetWriters(defaultW)
 }
 
 // Disassocia tutti i writer per un certo livello e ne reimposta un writer di default
 // (il writer di default riceve le eventuali loggate di errore o di feedback da parte degli altri writer).
-// NON thread safe.
-func ResetLevelWriters(level Level, defaultW Writer) {
-	globalDispatcher.ResetLevelWriters(level, defaultW)
+func ResetLevelWriters(level Level, defaultW Writer) error {
+	return DefaultSystem().ResetLevelWriters(level, defaultW)
 }
 
 // Disassocia tutti i writer per un set di livelli e ne reimposta un writer di default
 // (il writer di default riceve le eventuali loggate di errore o di feedback da parte degli altri writer).
-// NON thread safe.
-func ResetLevelsWriters(levels []Level, defaultW Writer) {
-	globalDispatcher.ResetLevelsWriters(levels, defaultW)
+func ResetLevelsWriters(levels []Level, defaultW Writer) error {
+	return DefaultSystem().ResetLevelsWriters(levels, defaultW)
 }
 
 // Associa un writer a tutti i livelli.
-// NON thread safe.
-func AddWriter(w Writer) {
-	globalDispatcher.AddWriter(w)
+func AddWriter(w Writer) error {
+	return DefaultSystem().AddWriter(w)
 }
 
 // Associa un writer a uno specifico livello.
-// NON thread safe.
-func AddLevelWriter(level Level, w Writer) {
-	globalDispatcher.AddLevelWriter(level, w)
+func AddLevelWriter(level Level, w Writer) error {
+	return DefaultSystem().AddLevelWriter(level, w)
 }
 
 // Associa un writer a un set di livelli.
-// NON thread safe.
-func AddLevelsWriter(levels []Level, w Writer) {
-	globalDispatcher.AddLevelsWriter(levels, w)
+func AddLevelsWriter(levels []Level, w Writer) error {
+	return DefaultSystem().AddLevelsWriter(levels, w)
+}
+
+// Associazione di un writer a un set di livelli.
+type WriterRoute struct {
+	Writer Writer
+
+	// Livelli a cui associare il writer (nil = tutti).
+	Levels []Level
+}
+
+// Sostituisce atomicamente tutte le associazioni dei writer con un writer di default
+// e un insieme di associazioni: gli item vengono inviati o alla vecchia o alla nuova configurazione.
+// I writer già associati e presenti nella nuova configurazione continuano a ricevere item senza essere riavviati,
+// quelli non più presenti vengono stoppati gentilmente dopo aver smesso di riceverne.
+func ReplaceWriters(defaultW Writer, routes []WriterRoute) error {
+	return DefaultSystem().ReplaceWriters(defaultW, routes)
+}
+
+// Disassocia un writer da tutti i livelli, stoppandolo.
+// Ritorna errore se nessun writer ha l'ID specificato.
+func RemoveWriter(id string) error {
+	return DefaultSystem().RemoveWriter(id)
+}
+
+// Disassocia un writer da uno specifico livello, stoppandolo se non più associato ad alcun livello.
+// Se si trattava del writer di default, il livello ne rimane privo.
+// Ritorna errore se nessun writer ha l'ID specificato.
+func RemoveLevelWriter(level Level, id string) error {
+	return DefaultSystem().RemoveLevelWriter(level, id)
+}
+
+// Informazioni su un writer associato al dispatcher.
+type WriterInfo struct {
+	ID   string
+	Name string
+
+	// Livelli a cui il writer è associato.
+	Levels []Level
+	// Livelli di cui il writer è il writer di default.
+	DefaultLevels []Level
+
+	// Il writer è stato avviato dal dispatcher.
+	Started bool
+}
+
+// Ritorna le informazioni su tutti i writer associati ad almeno un livello.
+func Writers() []WriterInfo {
+	return DefaultSystem().Writers()
+}
+
+// Imposta la finestra di soppressione dei duplicati (0 = disabilitata, default).
+// Gli item identici (stesso livello, prefisso e messaggio) successivi al primo
+// e generati entro la finestra non vengono inviati ai writer;
+// alla chiusura della finestra ne viene inviato uno riepilogativo con il numero di ripetizioni.
+func SetDedupWindow(window time.Duration) {
+	DefaultSystem().SetDedupWindow(window)
+}
+
+// Imposta un rate limit (token bucket) per un livello: al più perSecond item al secondo,
+// con picchi fino a burst item (perSecond <= 0 = disabilitato, default).
+// Il numero di item scartati viene riportato ogni RateLimitReportInterval.
+// Il livello fatal non è mai limitato.
+func SetRateLimit(level Level, perSecond float64, burst int) {
+	DefaultSystem().SetRateLimit(level, perSecond, burst)
+}
+
+// Attende che i writer asincroni abbiano consegnato gli item accodati
+// e che le eventuali loggate di feedback dei writer siano state inviate ai writer di default,
+// al più per timeout; i writer devono implementare Flusher (vedi writers.Writer).
+// Ritorna errore se il timeout scade prima.
+func Flush(timeout time.Duration) error {
+	return DefaultSystem().Flush(timeout)
 }
 
 // Mute mute/unmute a specific level.
 func Mute(level Level, state bool) {
-	globalDispatcher.Mute(level, state)
+	DefaultSystem().Mute(level, state)
+}
+
+// I metodi seguenti agiscono sul sistema a cui appartiene il logger (vedi System),
+// e quindi su tutti i logger del sistema: per i logger del sistema di default
+// equivalgono alle omonime funzioni del package e valgono per l'intero processo.
+// Per writer e ciclo di vita propri di un componente usare NewIsolatedLogger().
+
+// Avvia tutti i writer del sistema del logger.
+// Per i logger del sistema di default equivale a sparalog.Start(), e avvia quindi
+// i writer dell'intero processo.
+func (l *Logger) Start() error {
+	return l.getDispatcher().Start()
+}
+
+// Termina i writer del sistema del logger attendendo gentilmente il termine dei writer asincroni.
+// Per i logger del sistema di default equivale a sparalog.Stop(), e termina quindi
+// il logging dell'intero processo.
+func (l *Logger) Stop() {
+	l.getDispatcher().Stop()
+}
+
+// Attende che i writer asincroni del sistema del logger abbiano consegnato gli item accodati,
+// al più per timeout (vedi Flush()).
+func (l *Logger) Flush(timeout time.Duration) error {
+	return l.getDispatcher().Flush(timeout)
+}
+
+// Disassocia tutti i writer del logger e reimposta un writer di default.
+// La modifica vale per tutti i logger del sistema: per quelli del sistema di default, per l'intero processo.
+func (l *Logger) ResetWriters(defaultW Writer) error {
+	return l.getDispatcher().ResetWriters(defaultW)
+}
+
+// Disassocia tutti i writer del logger per un certo livello e ne reimposta un writer di default.
+// La modifica vale per tutti i logger del sistema: per quelli del sistema di default, per l'intero processo.
+func (l *Logger) ResetLevelWriters(level Level, defaultW Writer) error {
+	return l.getDispatcher().ResetLevelWriters(level, defaultW)
+}
+
+// Disassocia tutti i writer del logger per un set di livelli e ne reimposta un writer di default.
+// La modifica vale per tutti i logger del sistema: per quelli del sistema di default, per l'intero processo.
+func (l *Logger) ResetLevelsWriters(levels []Level, defaultW Writer) error {
+	return l.getDispatcher().ResetLevelsWriters(levels, defaultW)
+}
+
+// Associa un writer del logger a tutti i livelli.
+// La modifica vale per tutti i logger del sistema: per quelli del sistema di default, per l'intero processo.
+func (l *Logger) AddWriter(w Writer) error {
+	return l.getDispatcher().AddWriter(w)
+}
+
+// Associa un writer del logger a uno specifico livello.
+// La modifica vale per tutti i logger del sistema: per quelli del sistema di default, per l'intero processo.
+func (l *Logger) AddLevelWriter(level Level, w Writer) error {
+	return l.getDispatcher().AddLevelWriter(level, w)
+}
+
+// Associa un writer del logger a un set di livelli.
+// La modifica vale per tutti i logger del sistema: per quelli del sistema di default, per l'intero processo.
+func (l *Logger) AddLevelsWriter(levels []Level, w Writer) error {
+	return l.getDispatcher().AddLevelsWriter(levels, w)
+}
+
+// Sostituisce atomicamente tutte le associazioni dei writer del logger.
+// La modifica vale per tutti i logger del sistema: per quelli del sistema di default, per l'intero processo.
+func (l *Logger) ReplaceWriters(defaultW Writer, routes []WriterRoute) error {
+	return l.getDispatcher().ReplaceWriters(defaultW, routes)
+}
+
+// Disassocia un writer del logger da tutti i livelli, stoppandolo.
+// La modifica vale per tutti i logger del sistema: per quelli del sistema di default, per l'intero processo.
+func (l *Logger) RemoveWriter(id string) error {
+	return l.getDispatcher().RemoveWriter(id)
+}
+
+// Disassocia un writer del logger da uno specifico livello, stoppandolo se non più associato ad alcun livello.
+// La modifica vale per tutti i logger del sistema: per quelli del sistema di default, per l'intero processo.
+func (l *Logger) RemoveLevelWriter(level Level, id string) error {
+	return l.getDispatcher().RemoveLevelWriter(level, id)
+}
+
+// Ritorna le informazioni su tutti i writer del logger associati ad almeno un livello.
+func (l *Logger) Writers() []WriterInfo {
+	return l.getDispatcher().Writers()
+}
+
+// Imposta la finestra di soppressione dei duplicati del logger (vedi SetDedupWindow).
+// La modifica vale per tutti i logger del sistema: per quelli del sistema di default, per l'intero processo.
+func (l *Logger) SetDedupWindow(window time.Duration) {
+	l.getDispatcher().throttle.SetDedupWindow(window)
+}
+
+// Imposta un rate limit per un livello del logger (vedi SetRateLimit).
+// La modifica vale per tutti i logger del sistema: per quelli del sistema di default, per l'intero processo.
+func (l *Logger) SetRateLimit(level Level, perSecond float64, burst int) {
+	l.getDispatcher().throttle.SetRateLimit(level, perSecond, burst)
+}
+
+// Muta o smuta un livello del logger.
+// La modifica vale per tutti i logger del sistema: per quelli del sistema di default, per l'intero processo.
+func (l *Logger) Mute(level Level, state bool) {
+	l.getDispatcher().Mute(level, state)
 }
@@ -0,0 +1,129 @@
+package logtest
+
+// Asserzioni sugli item registrati.
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/modulo-srl/sparalog/logs"
+)
+
+// Condizione aggiuntiva che un item deve soddisfare.
+type Matcher func(*logs.Item) bool
+
+// Richiede che il payload contenga la chiave con un valore uguale (reflect.DeepEqual) a value.
+func Payload(key string, value any) Matcher {
+	return func(item *logs.Item) bool {
+		v, ok := item.Payload[key]
+		return ok && reflect.DeepEqual(v, value)
+	}
+}
+
+// Richiede che il payload contenga la chiave, con qualsiasi valore.
+func HasPayload(key string) Matcher {
+	return func(item *logs.Item) bool {
+		_, ok := item.Payload[key]
+		return ok
+	}
+}
+
+// Richiede che l'item abbia il prefisso specificato.
+func Prefix(prefix string) Matcher {
+	return func(item *logs.Item) bool {
+		return item.Prefix == prefix
+	}
+}
+
+// Verifica che sia stato registrato almeno un item del livello specificato (o AnyLevel)
+// il cui messaggio contiene substr e che soddisfa tutti i matcher; ritorna il primo item trovato.
+// Prima della verifica attende la consegna degli item dei writer asincroni (vedi DrainTimeout).
+func (r *Recorder) AssertLogged(tb testing.TB, level logs.Level, substr string, matchers ...Matcher) *logs.Item {
+	tb.Helper()
+
+	r.drain(tb)
+
+	found := r.Find(level, substr, matchers...)
+	if len(found) == 0 {
+		tb.Errorf("logtest: no %s item containing %q logged\n%s", levelName(level), substr, r.dump())
+		return nil
+	}
+
+	return found[0]
+}
+
+// Verifica che non sia stato registrato alcun item del livello specificato (o AnyLevel)
+// il cui messaggio contiene substr e che soddisfa tutti i matcher.
+// Prima della verifica attende la consegna degli item dei writer asincroni (vedi DrainTimeout).
+func (r *Recorder) AssertNotLogged(tb testing.TB, level logs.Level, substr string, matchers ...Matcher) {
+	tb.Helper()
+
+	r.drain(tb)
+
+	found := r.Find(level, substr, matchers...)
+	if len(found) > 0 {
+		tb.Errorf("logtest: unexpected %s item containing %q logged\n%s", levelName(level), substr, r.dump())
+	}
+}
+
+// Come Recorder.AssertLogged(), sul recorder installato per il test con Install().
+func AssertLogged(tb testing.TB, level logs.Level, substr string, matchers ...Matcher) *logs.Item {
+	tb.Helper()
+
+	return installedRecorder(tb).AssertLogged(tb, level, substr, matchers...)
+}
+
+// Come Recorder.AssertNotLogged(), sul recorder installato per il test con Install().
+func AssertNotLogged(tb testing.TB, level logs.Level, substr string, matchers ...Matcher) {
+	tb.Helper()
+
+	installedRecorder(tb).AssertNotLogged(tb, level, substr, matchers...)
+}
+
+func installedRecorder(tb testing.TB) *Recorder {
+	tb.Helper()
+
+	r, ok := installed.Load(tb)
+	if !ok {
+		tb.Fatal("logtest: no recorder installed, see Install()")
+	}
+
+	return r.(*Recorder)
+}
+
+func (r *Recorder) drain(tb testing.TB) {
+	tb.Helper()
+
+	err := r.Drain(DrainTimeout)
+	if err != nil {
+		tb.Errorf("logtest: %v", err)
+	}
+}
+
+// Ritorna l'elenco degli item registrati, per i messaggi di errore.
+func (r *Recorder) dump() string {
+	items := r.Items()
+	if len(items) == 0 {
+		return "no items logged"
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%d items logged:", len(items))
+
+	for _, item := range items {
+		sb.WriteString("\n\t")
+		sb.Write(r.FormatItem(item))
+	}
+
+	return sb.String()
+}
+
+func levelName(level logs.Level) string {
+	if level == AnyLevel {
+		return "any level"
+	}
+
+	return level.String()
+}
@@ -0,0 +1,198 @@
+package logtest
+
+// Writer di registrazione degli item per gli unit test.
+
+import (
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/modulo-srl/sparalog/logs"
+	"github.com/modulo-srl/sparalog/writers"
+)
+
+// Attesa massima della consegna degli item da parte dei writer asincroni,
+// prima di ogni verifica delle asserzioni.
+var DrainTimeout = 5 * time.Second
+
+// Livello jolly per le ricerche e le asserzioni: corrisponde a qualsiasi livello.
+const AnyLevel logs.Level = -1
+
+// Writer sincrono che registra tutti gli item ricevuti,
+// riportandoli eventualmente con t.Log() in modo che compaiano nell'output dei test falliti.
+type Recorder struct {
+	writers.Writer
+
+	mu    sync.Mutex
+	items []*logs.Item
+
+	tb testing.TB
+
+	// Attende la consegna degli item dei writer asincroni del dispatcher a cui è associato.
+	flush func(time.Duration) error
+}
+
+// Ritorna un recorder non associato; se tb non è nil gli item ricevuti
+// vengono riportati con tb.Log().
+func NewRecorder(tb testing.TB) *Recorder {
+	return &Recorder{
+		tb: tb,
+	}
+}
+
+// Imposta un recorder come writer di default di tutti i livelli del logger di default,
+// in sostituzione dei writer associati: riceve quindi anche le loggate di feedback
+// degli altri writer, associabili successivamente. Gli item ricevuti vengono riportati
+// con tb.Log() fino al termine del test.
+// Le funzioni AssertLogged() e AssertNotLogged() del package verificano gli item di questo recorder.
+// Va chiamata dopo sparalog.InitUnitTest().
+func Install(tb testing.TB) *Recorder {
+	tb.Helper()
+
+	r := NewRecorder(tb)
+	r.flush = logs.Flush
+
+	err := logs.ResetWriters(r)
+	if err != nil {
+		tb.Fatalf("logtest: %v", err)
+	}
+
+	installed.Store(tb, r)
+
+	tb.Cleanup(func() {
+		installed.Delete(tb)
+		r.detach()
+	})
+
+	return r
+}
+
+// Ritorna un logger isolato (vedi logs.NewIsolatedLogger()), già avviato, con un recorder come writer di default;
+// il logger viene stoppato al termine del test.
+func NewLogger(tb testing.TB, prefix string) (*logs.Logger, *Recorder) {
+	tb.Helper()
+
+	r := NewRecorder(tb)
+
+	l := logs.NewIsolatedLogger(prefix, r)
+	r.flush = l.Flush
+
+	err := l.Start()
+	if err != nil {
+		tb.Fatalf("logtest: %v", err)
+	}
+
+	tb.Cleanup(func() {
+		l.Stop()
+		r.detach()
+	})
+
+	return l, r
+}
+
+// Ritorna un nuovo sistema di logging (vedi logs.NewSystem()), già avviato, con un recorder come writer di default;
+// il sistema viene stoppato al termine del test. Essendo indipendente dal sistema di default
+// è utilizzabile nei test paralleli.
+func NewSystem(tb testing.TB) (*logs.System, *Recorder) {
+	tb.Helper()
+
+	r := NewRecorder(tb)
+
+	s := logs.NewSystem(r)
+	r.flush = s.Flush
+
+	err := s.Start()
+	if err != nil {
+		tb.Fatalf("logtest: %v", err)
+	}
+
+	tb.Cleanup(func() {
+		s.Stop()
+		r.detach()
+	})
+
+	return s, r
+}
+
+// Recorder installati per test, vedi Install().
+var installed sync.Map
+
+// Non implementa logs.PoolSafeWriter, dato che gli item vengono conservati.
+func (r *Recorder) Write(item *logs.Item) {
+	r.mu.Lock()
+	r.items = append(r.items, item)
+	tb := r.tb
+	r.mu.Unlock()
+
+	if tb != nil {
+		tb.Log(string(r.FormatItem(item)))
+	}
+}
+
+// Smette di riportare gli item con tb.Log(), non più invocabile al termine del test.
+func (r *Recorder) detach() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.tb = nil
+}
+
+// Ritorna una copia degli item registrati, nell'ordine di ricezione.
+func (r *Recorder) Items() []*logs.Item {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]*logs.Item(nil), r.items...)
+}
+
+// Scarta gli item registrati.
+func (r *Recorder) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.items = nil
+}
+
+// Attende che i writer asincroni del dispatcher a cui il recorder è associato
+// abbiano consegnato gli item accodati, al più per timeout.
+// Non ha effetto se il recorder non è stato associato con Install() o NewLogger().
+func (r *Recorder) Drain(timeout time.Duration) error {
+	if r.flush == nil {
+		return nil
+	}
+
+	return r.flush(timeout)
+}
+
+// Ritorna gli item registrati del livello specificato (o AnyLevel) il cui messaggio
+// contiene substr e che soddisfano tutti i matcher.
+func (r *Recorder) Find(level logs.Level, substr string, matchers ...Matcher) []*logs.Item {
+	var found []*logs.Item
+
+	for _, item := range r.Items() {
+		if match(item, level, substr, matchers) {
+			found = append(found, item)
+		}
+	}
+
+	return found
+}
+
+func match(item *logs.Item, level logs.Level, substr string, matchers []Matcher) bool {
+	if level != AnyLevel && item.Level != level {
+		return false
+	}
+
+	if !strings.Contains(item.Message, substr) {
+		return false
+	}
+
+	for _, m := range matchers {
+		if !m(item) {
+			return false
+		}
+	}
+
+	return true
+}
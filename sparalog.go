@@ -8,6 +8,15 @@ import (
 	"github.com/modulo-srl/sparalog/writers"
 )
 
+// Sistema di logging indipendente, vedi logs.System.
+type System = logs.System
+
+// Alloca un nuovo sistema di logging indipendente da quello di default,
+// con un writer di default di tipo stdout; va avviato con Start() e terminato con Stop().
+func NewSystem() *System {
+	return logs.NewSystem(writers.NewStdoutWriter())
+}
+
 // Avvia il logger di default.
 // Va chiamata una volta che i writer sono stati inizializzati e associati.
 func Start() {
@@ -37,4 +46,6 @@ func InitUnitTest() {
 func initSparalog() {
 	w := writers.NewStdoutWriter()
 	logs.InitDefaultLogger(w)
+
+	resetConfigState()
 }
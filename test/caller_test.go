@@ -0,0 +1,115 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/modulo-srl/sparalog"
+	"github.com/modulo-srl/sparalog/logs"
+	"github.com/modulo-srl/sparalog/writers"
+)
+
+func TestCaller(t *testing.T) {
+	sparalog.InitUnitTest()
+
+	var last *logs.Item
+
+	w := writers.NewCallbackWriter(
+		func(item *logs.Item) error {
+			last = item
+			return nil
+		},
+	)
+	logs.ResetWriters(w)
+
+	sparalog.Start()
+	defer sparalog.Stop()
+
+	// Disabilitato di default.
+	logs.Info("no caller")
+	if last.Caller != nil {
+		t.Errorf("unexpected caller: %+v", last.Caller)
+	}
+
+	logs.EnableLevelsCaller([]logs.Level{logs.InfoLevel, logs.ErrorLevel})
+	defer logs.EnableLevelsCaller(nil)
+
+	check := func(name string) {
+		t.Helper()
+
+		if last.Caller == nil {
+			t.Errorf("%s: missing caller", name)
+			return
+		}
+
+		if !strings.HasSuffix(last.Caller.Function, "TestCaller") {
+			t.Errorf("%s: unexpected caller function: %s", name, last.Caller.Function)
+		}
+
+		if s := last.Caller.Short(); !strings.HasPrefix(s, "test/caller_test.go:") {
+			t.Errorf("%s: unexpected short caller: %s", name, s)
+		}
+	}
+
+	logs.Info("package")
+	check("package")
+
+	logs.Infof("package %s", "f")
+	check("package f")
+
+	logs.InfoContext(context.Background(), "package context")
+	check("package context")
+
+	logger := logs.NewLogger("caller")
+
+	logger.Info("logger")
+	check("logger")
+
+	logger.With("k", "v").Infof("derived %s", "f")
+	check("derived")
+
+	logger.Error("logger error")
+	check("logger error")
+
+	// Con lo stacktrace abilitato il caller coincide con il primo frame.
+	if f := last.Stack.Frames[0]; f != *last.Caller {
+		t.Errorf("caller %+v differs from first frame %+v", last.Caller, f)
+	}
+
+	// Livello non abilitato.
+	logger.Warning("no caller")
+	if last.Caller != nil {
+		t.Errorf("unexpected caller: %+v", last.Caller)
+	}
+
+	// Rendering dei formatter.
+	logger.Info("message")
+	short := last.Caller.Short()
+
+	s := string((&logs.TextFormatter{}).Format(nil, last))
+	if !strings.Contains(s, "[caller] "+short+": message") {
+		t.Errorf("unexpected text: %s", s)
+	}
+
+	s = string(logs.NewLogfmtFormatter().Format(nil, last))
+	if !strings.Contains(s, " prefix=caller caller="+short+" msg=message") {
+		t.Errorf("unexpected logfmt: %s", s)
+	}
+
+	bb, err := json.Marshal(last)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var item logs.Item
+	err = json.Unmarshal(bb, &item)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if item.Caller == nil || *item.Caller != *last.Caller {
+		t.Errorf("unexpected decoded caller: %+v", item.Caller)
+	}
+}
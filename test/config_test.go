@@ -0,0 +1,206 @@
+package test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/modulo-srl/sparalog"
+	"github.com/modulo-srl/sparalog/logs"
+	"github.com/modulo-srl/sparalog/writers"
+)
+
+func TestConfigure(t *testing.T) {
+	sparalog.InitUnitTest()
+
+	received := map[string][]string{}
+
+	sparalog.RegisterWriterType("memory", func(params json.RawMessage) (logs.Writer, error) {
+		var p struct {
+			Key string `json:"key"`
+		}
+
+		err := sparalog.DecodeWriterParams(params, &p)
+		if err != nil {
+			return nil, err
+		}
+
+		return writers.NewCallbackWriter(func(item *logs.Item) error {
+			received[p.Key] = append(received[p.Key], item.Message)
+			return nil
+		}), nil
+	})
+
+	t.Setenv(sparalog.EnvStackTrace, "fatal")
+
+	err := sparalog.Configure(strings.NewReader(`{
+		"writers": {
+			"main": {"type": "memory", "params": {"key": "main"}},
+			"critical": {"type": "memory", "levels": ["fatal", "error"], "format": "json", "params": {"key": "critical"}}
+		},
+		"default": "main",
+		"mute": ["info"],
+		"stacktrace": ["fatal", "error"],
+		"levels": "db=error"
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sparalog.Start()
+	defer sparalog.Stop()
+
+	logs.Error("error")
+	logs.Warning("warning")
+	logs.Info("info")
+	logs.Debug("debug")
+	logs.GetLogger("db").Warning("db warning")
+
+	if s := strings.Join(received["main"], ","); s != "error,warning,debug" {
+		t.Errorf("main writer: %s", s)
+	}
+	if s := strings.Join(received["critical"], ","); s != "error" {
+		t.Errorf("critical writer: %s", s)
+	}
+
+	names := map[string]bool{}
+	for _, info := range logs.Writers() {
+		names[info.Name] = true
+	}
+	if !names["main"] || !names["critical"] {
+		t.Errorf("unexpected writer names: %v", names)
+	}
+
+	// Stacktrace sovrascritto dall'ambiente.
+	var last *logs.Item
+	logs.ResetWriters(writers.NewCallbackWriter(func(item *logs.Item) error {
+		last = item
+		return nil
+	}))
+	logs.Error("no stack")
+	if last == nil || last.Stack != nil {
+		t.Errorf("unexpected stacktrace: %+v", last)
+	}
+}
+
+func TestConfigureErrors(t *testing.T) {
+	sparalog.InitUnitTest()
+
+	configs := map[string]string{
+		"unknown type":    `{"writers": {"w": {"type": "nope"}}}`,
+		"unknown level":   `{"writers": {"w": {"type": "stdout", "levels": ["nope"]}}}`,
+		"unknown format":  `{"writers": {"w": {"type": "stdout", "format": "nope"}}}`,
+		"unknown param":   `{"writers": {"w": {"type": "stdout", "params": {"nope": 1}}}}`,
+		"unknown field":   `{"nope": 1}`,
+		"missing default": `{"default": "w"}`,
+		"default levels":  `{"writers": {"w": {"type": "stdout", "levels": ["error"]}}, "default": "w"}`,
+	}
+
+	for name, cfg := range configs {
+		err := sparalog.Configure(strings.NewReader(cfg))
+		if err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
+
+func TestConfigureStartError(t *testing.T) {
+	sparalog.InitUnitTest()
+	sparalog.Start()
+	defer sparalog.Stop()
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	port := l.Addr().(*net.TCPAddr).Port
+	l.Close()
+
+	// Il secondo writer non riesce ad avviarsi: il primo viene stoppato sia dal rollback
+	// del dispatcher che dalla configurazione.
+	cfg := fmt.Sprintf(`{"writers": {
+		"a": {"type": "tcp", "params": {"address": "127.0.0.1", "port": %d}},
+		"b": {"type": "tcp", "params": {"address": "127.0.0.1", "port": %d}}
+	}}`, port, port)
+
+	err = sparalog.Configure(strings.NewReader(cfg))
+	if err == nil {
+		t.Fatal("expected error")
+	}
+
+	logs.Info("still working")
+}
+
+func TestConfigureLevels(t *testing.T) {
+	sparalog.InitUnitTest()
+
+	err := sparalog.Configure(strings.NewReader(`{"levels": "db=error"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Regole non valide: la configurazione viene scartata prima di sostituire i writer.
+	err = sparalog.Configure(strings.NewReader(`{"writers": {"a": {"type": "stdout"}}, "levels": "db=nope"}`))
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	for _, info := range logs.Writers() {
+		if info.Name == "a" {
+			t.Error("writers replaced by an invalid config")
+		}
+	}
+
+	var received []string
+	logs.ResetWriters(writers.NewCallbackWriter(func(item *logs.Item) error {
+		received = append(received, item.Message)
+		return nil
+	}))
+
+	db := logs.GetLogger("db")
+	db.Warning("filtered")
+
+	// Levels omessa: le regole precedenti vengono rimosse.
+	err = sparalog.Configure(strings.NewReader(`{}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	logs.ResetWriters(writers.NewCallbackWriter(func(item *logs.Item) error {
+		received = append(received, item.Message)
+		return nil
+	}))
+
+	db.Warning("not filtered")
+
+	if s := strings.Join(received, ","); s != "not filtered" {
+		t.Errorf("received: %s", s)
+	}
+}
+
+func TestConfigureConcurrentLogging(t *testing.T) {
+	sparalog.InitUnitTest()
+
+	sparalog.RegisterWriterType("discard", func(params json.RawMessage) (logs.Writer, error) {
+		return writers.NewCallbackWriter(func(item *logs.Item) error {
+			return nil
+		}), nil
+	})
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 100; i++ {
+			logs.Error("error")
+		}
+	}()
+
+	for i := 0; i < 10; i++ {
+		err := sparalog.Configure(strings.NewReader(`{"writers": {"d": {"type": "discard"}}, "default": "d", "stacktrace": ["error"]}`))
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	<-done
+}
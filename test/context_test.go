@@ -0,0 +1,114 @@
+package test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/modulo-srl/sparalog"
+	"github.com/modulo-srl/sparalog/logs"
+	"github.com/modulo-srl/sparalog/writers"
+)
+
+type requestIDKey struct{}
+
+func TestContext(t *testing.T) {
+	sparalog.InitUnitTest()
+
+	var last *logs.Item
+
+	w := writers.NewCallbackWriter(
+		func(item *logs.Item) error {
+			last = item
+			return nil
+		},
+	)
+	logs.ResetWriters(w)
+
+	sparalog.Start()
+	defer sparalog.Stop()
+
+	logs.RegisterContextKey("request_id", requestIDKey{})
+
+	// Senza logger nel contesto viene usato quello di default.
+	ctx := context.Background()
+	if logs.FromContext(ctx) == nil {
+		t.Fatal("no default logger from context")
+	}
+
+	logger := logs.NewLogger("http")
+	logger.SetPayload("component", "api")
+
+	ctx = logs.NewContext(ctx, logger)
+	ctx = context.WithValue(ctx, requestIDKey{}, "req-1")
+	ctx = logs.ContextWithPayload(ctx, "user_id", 42)
+
+	if logs.FromContext(ctx) != logger {
+		t.Fatal("logger not carried by context")
+	}
+
+	logs.InfoContext(ctx, "handled")
+
+	if last == nil || last.Prefix != "http" || last.Message != "handled" {
+		t.Fatalf("unexpected item: %+v", last)
+	}
+
+	expected := map[string]any{"component": "api", "request_id": "req-1", "user_id": 42}
+	for k, v := range expected {
+		if last.Payload[k] != v {
+			t.Errorf("payload %s: expected %v, got %v", k, v, last.Payload[k])
+		}
+	}
+
+	// Il payload del logger non viene modificato.
+	logger.Info("plain")
+	if len(last.Payload) != 1 {
+		t.Errorf("logger payload modified: %v", last.Payload)
+	}
+
+	// Il payload del contesto padre non viene modificato.
+	child := logs.ContextWithPayload(ctx, "user_id", 43)
+	logger.WarningContext(child, "child")
+	if last.Payload["user_id"] != 43 {
+		t.Errorf("child payload: %v", last.Payload)
+	}
+	logger.WarningContext(ctx, "parent")
+	if last.Payload["user_id"] != 42 {
+		t.Errorf("parent payload: %v", last.Payload)
+	}
+}
+
+func TestContextStacktrace(t *testing.T) {
+	sparalog.InitUnitTest()
+
+	var last *logs.Item
+
+	w := writers.NewCallbackWriter(
+		func(item *logs.Item) error {
+			last = item
+			return nil
+		},
+	)
+	logs.ResetWriters(w)
+
+	sparalog.Start()
+	defer sparalog.Stop()
+
+	ctx := context.Background()
+	logs.ErrorContext(ctx, "default")
+	checkContextStacktrace(t, last)
+
+	logger := logs.NewLogger("ctx")
+	logs.ErrorContext(logs.NewContext(ctx, logger), "from context")
+	checkContextStacktrace(t, last)
+
+	logger.ErrorContext(ctx, "method")
+	checkContextStacktrace(t, last)
+}
+
+func checkContextStacktrace(t *testing.T, item *logs.Item) {
+	if item == nil || item.Stack == nil ||
+		!strings.HasSuffix(item.Stack.Frames[0].Function, "test.TestContextStacktrace") {
+		t.Errorf("invalid stacktrace for %q:\n%s", item.Message, item.StackTrace())
+	}
+}
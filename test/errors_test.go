@@ -0,0 +1,140 @@
+package test
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/modulo-srl/sparalog"
+	"github.com/modulo-srl/sparalog/logs"
+	"github.com/modulo-srl/sparalog/writers"
+)
+
+// Errore di dominio con campi e stacktrace.
+type notFoundError struct {
+	id  int
+	pcs []uintptr
+}
+
+func newNotFoundError(id int) error {
+	pcs := make([]uintptr, 32)
+	n := runtime.Callers(2, pcs)
+
+	return &notFoundError{id: id, pcs: pcs[:n]}
+}
+
+func (e *notFoundError) Error() string             { return fmt.Sprintf("entity %d not found", e.id) }
+func (e *notFoundError) LogFields() map[string]any { return map[string]any{"id": e.id, "code": 404} }
+func (e *notFoundError) Callers() []uintptr        { return e.pcs }
+
+// Errore con stacktrace nello stile di github.com/pkg/errors.
+type pkgFrame uintptr
+type pkgStackTrace []pkgFrame
+
+type pkgError struct {
+	msg   string
+	stack pkgStackTrace
+}
+
+func newPkgError(msg string) error {
+	pcs := make([]uintptr, 32)
+	n := runtime.Callers(2, pcs)
+
+	e := &pkgError{msg: msg}
+	for _, pc := range pcs[:n] {
+		e.stack = append(e.stack, pkgFrame(pc))
+	}
+
+	return e
+}
+
+func (e *pkgError) Error() string             { return e.msg }
+func (e *pkgError) StackTrace() pkgStackTrace { return e.stack }
+
+// Errore esterno che sovrascrive un campo di quello interno.
+type codeError struct {
+	err error
+}
+
+func (e *codeError) Error() string             { return "request failed: " + e.err.Error() }
+func (e *codeError) Unwrap() error             { return e.err }
+func (e *codeError) LogFields() map[string]any { return map[string]any{"code": 500} }
+
+func TestErrorChain(t *testing.T) {
+	sparalog.InitUnitTest()
+
+	var last *logs.Item
+
+	w := writers.NewCallbackWriter(
+		func(item *logs.Item) error {
+			last = item
+			return nil
+		},
+	)
+	logs.ResetWriters(w)
+
+	sparalog.Start()
+	defer sparalog.Stop()
+
+	base := newNotFoundError(7)
+	err := &codeError{fmt.Errorf("loading: %w", base)}
+
+	logs.Err(err)
+
+	if last == nil || last.Level != logs.ErrorLevel || last.Message != err.Error() {
+		t.Fatalf("unexpected item: %+v", last)
+	}
+
+	if last.Payload["id"] != 7 || last.Payload["code"] != 500 {
+		t.Errorf("unexpected fields: %v", last.Payload)
+	}
+
+	causes, _ := last.Payload["causes"].([]any)
+	if len(causes) != 2 {
+		t.Fatalf("unexpected causes: %v", last.Payload["causes"])
+	}
+	if c := causes[1].(map[string]any); c["message"] != "entity 7 not found" || c["type"] != "*test.notFoundError" {
+		t.Errorf("unexpected cause: %v", c)
+	}
+
+	// Lo stacktrace è quello di creazione dell'errore.
+	if last.Stack == nil || len(last.Stack.Frames) == 0 ||
+		!strings.HasSuffix(last.Stack.Frames[0].Function, "TestErrorChain") {
+		t.Errorf("unexpected stack: %+v", last.Stack)
+	}
+
+	// Errori multipli.
+	logger := logs.NewLogger("join")
+	logger.ErrLevel(logs.WarningLevel, errors.Join(
+		fmt.Errorf("first: %w", errors.New("inner")),
+		newPkgError("second"),
+	))
+
+	if last.Level != logs.WarningLevel || last.Prefix != "join" {
+		t.Fatalf("unexpected item: %+v", last)
+	}
+
+	causes, _ = last.Payload["causes"].([]any)
+	if len(causes) != 2 {
+		t.Fatalf("unexpected causes: %v", last.Payload["causes"])
+	}
+
+	first := causes[0].(map[string]any)
+	nested, _ := first["causes"].([]any)
+	if first["message"] != "first: inner" || len(nested) != 1 || nested[0].(map[string]any)["message"] != "inner" {
+		t.Errorf("unexpected nested causes: %v", first)
+	}
+
+	if last.Stack == nil || len(last.Stack.Frames) == 0 ||
+		!strings.HasSuffix(last.Stack.Frames[0].Function, "TestErrorChain") {
+		t.Errorf("unexpected stack: %+v", last.Stack)
+	}
+
+	// Errori senza catena: nessuna causa.
+	item := logs.NewErrorItem(errors.New("plain"))
+	if _, ok := item.Payload["causes"]; ok {
+		t.Errorf("unexpected causes: %v", item.Payload)
+	}
+}
@@ -1,23 +1,179 @@
 package test
 
 import (
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/modulo-srl/sparalog"
 	"github.com/modulo-srl/sparalog/logs"
+	"github.com/modulo-srl/sparalog/writers"
 )
 
-// TODO
 func TestFatal(t *testing.T) {
 	sparalog.InitUnitTest()
 
+	var (
+		mu     sync.Mutex
+		events []string
+	)
+
+	event := func(s string) {
+		mu.Lock()
+		events = append(events, s)
+		mu.Unlock()
+	}
+
+	// Writer asincrono lento: la loggata fatale deve essere scritta prima della terminazione.
+	w := writers.NewCallbackAsyncWriter(
+		func(item *logs.Item) error {
+			time.Sleep(50 * time.Millisecond)
+			event("write " + item.Message)
+			return nil
+		},
+	)
+	logs.ResetWriters(w)
+
+	exitCode := -1
+	logs.SetExitFunc(func(code int) {
+		event("exit")
+		exitCode = code
+	})
+
+	logs.OnFatal(func(item *logs.Item) {
+		event("hook " + item.Message)
+		logs.Info("cleanup")
+	})
+	logs.OnFatal(func(item *logs.Item) {
+		panic("broken hook")
+	})
+
+	logs.SetFatalHooksTimeout(100 * time.Millisecond)
+
+	logs.OnFatal(func(item *logs.Item) {
+		time.Sleep(time.Second)
+		event("slow hook")
+	})
+
+	sparalog.Start()
+	defer sparalog.Stop()
+
+	start := time.Now()
+	logs.Fatal("fatal")
+
+	if time.Since(start) > 900*time.Millisecond {
+		t.Error("hooks deadline not honored")
+	}
+
+	if exitCode != logs.FatalExitCode {
+		t.Errorf("unexpected exit code %d", exitCode)
+	}
+
+	mu.Lock()
+	s := strings.Join(events, ",")
+	mu.Unlock()
+
+	if s != "hook fatal,write fatal,write cleanup,exit" {
+		t.Errorf("unexpected events: %s", s)
+	}
+
+	// Dopo il fatale il dispatcher è arrestato.
+	logs.Error("after fatal")
+	if exitCode != logs.FatalExitCode {
+		t.Error("exit invoked twice")
+	}
+}
+
+func TestFatalReentrant(t *testing.T) {
+	sparalog.InitUnitTest()
+
+	var (
+		mu     sync.Mutex
+		writes []string
+		exits  int
+	)
+
+	logs.ResetWriters(writers.NewCallbackWriter(func(item *logs.Item) error {
+		mu.Lock()
+		writes = append(writes, item.Message)
+		mu.Unlock()
+		return nil
+	}))
+
+	logs.SetExitFunc(func(code int) {
+		// Lascia alle loggate fatali concorrenti il tempo di accodarsi.
+		time.Sleep(50 * time.Millisecond)
+
+		mu.Lock()
+		exits++
+		mu.Unlock()
+	})
+
+	// Hook che logga a sua volta un fatale: non deve ricorrere.
+	logs.OnFatal(func(item *logs.Item) {
+		if item.Message == "fatal" {
+			logs.Fatal("fatal from hook")
+		}
+	})
+
+	logs.SetFatalHooksTimeout(100 * time.Millisecond)
+
 	sparalog.Start()
-	//logs.StartPanicWatcher()
+	defer sparalog.Stop()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			logs.Fatal("fatal")
+		}()
+	}
+
+	// Le loggate fatali successive alla prima ritornano solo dopo la terminazione.
+	wg.Wait()
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if exits != 1 {
+		t.Errorf("exit invoked %d times", exits)
+	}
 
-	//logs.Fatal("test fatal")
+	if len(writes) == 0 || writes[0] != "fatal" {
+		t.Errorf("unexpected writes: %v", writes)
+	}
 }
 
 // TODO
+func TestFatalHooksTimeoutPerSystem(t *testing.T) {
+	sparalog.InitUnitTest()
+
+	// Il timeout di un sistema non vale per gli altri.
+	logs.SetFatalHooksTimeout(time.Hour)
+
+	sys := logs.NewSystem(writers.NewCallbackWriter(func(item *logs.Item) error { return nil }))
+	sys.SetFatalHooksTimeout(50 * time.Millisecond)
+	sys.SetExitFunc(func(code int) {})
+
+	release := make(chan struct{})
+	defer close(release)
+	sys.OnFatal(func(item *logs.Item) {
+		<-release
+	})
+
+	sys.Start()
+	defer sys.Stop()
+
+	start := time.Now()
+	sys.Logger().Fatal("fatal")
+
+	if time.Since(start) > time.Second {
+		t.Error("system hooks timeout not honored")
+	}
+}
+
 func TestError(t *testing.T) {
 	sparalog.InitUnitTest()
 
@@ -39,3 +39,47 @@ func TestFile(t *testing.T) {
 		t.Fatal("mismatch: ", s)
 	}
 }
+
+func TestFileRestart(t *testing.T) {
+	sparalog.InitUnitTest()
+
+	os.Remove("test.log")
+	defer os.Remove("test.log")
+
+	w, err := writers.NewFileWriter("test.log")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	feedback := make(chan *logs.Item, 10)
+	w.SetFeedbackChan(feedback)
+
+	// Scartato e riportato all'avvio.
+	w.Write(logs.NewItem(logs.InfoLevel, "before-start"))
+
+	for _, msg := range []string{"first-run", "second-run"} {
+		if err := w.Start(); err != nil {
+			t.Fatal(err)
+		}
+		w.Write(logs.NewItem(logs.InfoLevel, msg))
+		w.Stop()
+	}
+
+	var reported []string
+	for len(feedback) > 0 {
+		reported = append(reported, (<-feedback).Message)
+	}
+	if s := strings.Join(reported, "\n"); !strings.HasSuffix(s, "queue not running: 1 items dropped") {
+		t.Errorf("unexpected feedback: %q", s)
+	}
+
+	bb, err := os.ReadFile("test.log")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := string(bb)
+	if !strings.Contains(s, "first-run") || !strings.Contains(s, "second-run") || strings.Contains(s, "before-start") {
+		t.Fatal("mismatch: ", s)
+	}
+}
@@ -0,0 +1,124 @@
+package test
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/modulo-srl/sparalog"
+	"github.com/modulo-srl/sparalog/logs"
+	"github.com/modulo-srl/sparalog/writers"
+)
+
+func TestFormatters(t *testing.T) {
+	sparalog.InitUnitTest()
+
+	i := logs.NewItem(logs.InfoLevel, "hello world")
+	i.Prefix = "db"
+	i.SetPayload("rows", 3)
+	i.SetPayload("by main", "a=b")
+
+	s := string(logs.NewTextFormatter().Format(nil, i))
+	if !strings.HasSuffix(s, `info [db]: hello world by_main="a=b" rows=3`) {
+		t.Errorf("text: %s", s)
+	}
+
+	s = string((&logs.TextFormatter{}).Format(nil, i))
+	if s != "info [db]: hello world" {
+		t.Errorf("text without payload: %s", s)
+	}
+
+	s = string(logs.NewLogfmtFormatter().Format(nil, i))
+	if !strings.HasPrefix(s, "ts=") ||
+		!strings.HasSuffix(s, ` level=info prefix=db msg="hello world" by_main="a=b" rows=3`) {
+		t.Errorf("logfmt: %s", s)
+	}
+
+	var m map[string]any
+	bb := logs.NewJSONFormatter().Format(nil, i)
+	if err := json.Unmarshal(bb, &m); err != nil {
+		t.Fatal(err, string(bb))
+	}
+	if m["level"] != "info" || m["prefix"] != "db" || m["message"] != "hello world" {
+		t.Errorf("json: %s", bb)
+	}
+}
+
+func TestFileFormatter(t *testing.T) {
+	sparalog.InitUnitTest()
+
+	sparalog.Start()
+	defer sparalog.Stop()
+
+	os.Remove("test.log")
+
+	w, err := writers.NewFileWriter("test.log")
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.SetFormatter(logs.NewLogfmtFormatter())
+	logs.ResetWriters(w)
+
+	logs.Info("test-file-logfmt")
+
+	time.Sleep(50 * time.Millisecond)
+
+	bb, err := os.ReadFile("test.log")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := string(bb)
+	if !strings.Contains(s, " level=info msg=test-file-logfmt\n") {
+		t.Fatal("mismatch: ", s)
+	}
+}
+
+func TestTextPayloadQuoting(t *testing.T) {
+	sparalog.InitUnitTest()
+
+	i := logs.NewItem(logs.InfoLevel, "quoting")
+	i.SetPayload("plain", "value")
+	i.SetPayload("empty", "")
+	i.SetPayload("spaced", "two words")
+	i.SetPayload("quote", `say "hi"`)
+	i.SetPayload("newline", "a\nb")
+	i.SetPayload("err", errors.New("boom"))
+
+	s := string((&logs.TextFormatter{Payload: true}).Format(nil, i))
+
+	expected := `info: quoting empty="" err=boom newline="a\nb" plain=value quote="say \"hi\"" spaced="two words"`
+	if s != expected {
+		t.Errorf("expected: %s\ngot: %s", expected, s)
+	}
+}
+
+type fieldError struct {
+	field string
+}
+
+func (e *fieldError) Error() string {
+	return e.field + " invalid"
+}
+
+func TestPayloadTypedNil(t *testing.T) {
+	sparalog.InitUnitTest()
+
+	var err *fieldError
+
+	i := logs.NewItem(logs.InfoLevel, "typed nil")
+	i.SetPayload("err", err)
+
+	s := string((&logs.TextFormatter{Payload: true}).Format(nil, i))
+	if s != "info: typed nil err=<nil>" {
+		t.Errorf("text: %s", s)
+	}
+
+	s = string((&logs.LogfmtFormatter{}).Format(nil, i))
+	if !strings.HasSuffix(s, "err=<nil>") {
+		t.Errorf("logfmt: %s", s)
+	}
+}
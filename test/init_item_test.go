@@ -1,6 +1,9 @@
 package test
 
 import (
+	"fmt"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -40,3 +43,58 @@ func testInitItemCallback(i *logs.Item) error {
 	lastPrefix = i.Prefix
 	return nil
 }
+
+func TestInitItemPayload(t *testing.T) {
+	sparalog.InitUnitTest()
+
+	var (
+		mu    sync.Mutex
+		items []*logs.Item
+	)
+
+	logger := logs.NewIsolatedLogger("payload", writers.NewCallbackWriter(func(item *logs.Item) error {
+		mu.Lock()
+		items = append(items, item)
+		mu.Unlock()
+		return nil
+	}))
+	logger.SetPayload("service", "api")
+
+	var n atomic.Int32
+	logger.SetInitItemFunc(func(item *logs.Item) {
+		item.SetPayload("id", n.Add(1))
+	})
+
+	logger.Start()
+	defer logger.Stop()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 10; j++ {
+				logger.Info("message")
+			}
+		}()
+	}
+	wg.Wait()
+
+	// Il payload condiviso del logger non viene modificato dagli item.
+	logger.SetInitItemFunc(nil)
+	item := logger.NewItem(logs.InfoLevel, "check")
+	if _, ok := item.Payload["id"]; ok || item.Payload["service"] != "api" {
+		t.Errorf("logger payload modified: %v", item.Payload)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	ids := map[string]bool{}
+	for _, item := range items {
+		ids[fmt.Sprint(item.Payload["id"])] = true
+	}
+	if len(items) != 100 || len(ids) != 100 {
+		t.Errorf("received %d items with %d distinct ids", len(items), len(ids))
+	}
+}
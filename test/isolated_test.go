@@ -0,0 +1,63 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/modulo-srl/sparalog"
+	"github.com/modulo-srl/sparalog/logs"
+	"github.com/modulo-srl/sparalog/writers"
+)
+
+func TestIsolatedLogger(t *testing.T) {
+	sparalog.InitUnitTest()
+
+	var mainMessages, auditMessages []string
+
+	mw := writers.NewCallbackWriter(
+		func(item *logs.Item) error {
+			mainMessages = append(mainMessages, item.Message)
+			return nil
+		},
+	)
+	logs.ResetWriters(mw)
+
+	sparalog.Start()
+	defer sparalog.Stop()
+
+	aw := writers.NewCallbackWriter(
+		func(item *logs.Item) error {
+			auditMessages = append(auditMessages, item.Message)
+			return nil
+		},
+	)
+	audit := logs.NewIsolatedLogger("audit", aw)
+
+	err := audit.Start()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer audit.Stop()
+
+	logs.Info("main")
+	audit.Info("audit")
+
+	if len(mainMessages) != 1 || mainMessages[0] != "main" {
+		t.Errorf("main logger: unexpected messages %v", mainMessages)
+	}
+	if len(auditMessages) != 1 || auditMessages[0] != "audit" {
+		t.Errorf("audit logger: unexpected messages %v", auditMessages)
+	}
+
+	// Il mute del logger isolato non deve toccare il dispatcher globale.
+	audit.Mute(logs.InfoLevel, true)
+
+	logs.Info("main 2")
+	audit.Info("audit 2")
+
+	if len(mainMessages) != 2 {
+		t.Errorf("main logger: unexpected messages %v", mainMessages)
+	}
+	if len(auditMessages) != 1 {
+		t.Errorf("audit logger: unexpected messages %v", auditMessages)
+	}
+}
@@ -0,0 +1,89 @@
+package test
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/modulo-srl/sparalog"
+	"github.com/modulo-srl/sparalog/logs"
+)
+
+type cyclic struct {
+	Name string
+	Next *cyclic
+}
+
+func TestJSONRoundTrip(t *testing.T) {
+	sparalog.InitUnitTest()
+
+	c := &cyclic{Name: "loop"}
+	c.Next = c
+
+	i := logs.NewItem(logs.ErrorLevel, "json test")
+	i.Prefix = "shipper"
+	i.SetPayload("rows", 3)
+	i.SetPayload("tags", []string{"a", "b"})
+	i.SetPayload("ch", make(chan int))
+	i.SetPayload("fn", func() {})
+	i.SetPayload("cyclic", c)
+
+	var buf bytes.Buffer
+	f := logs.NewJSONFormatter()
+	buf.Write(f.Format(nil, i))
+	buf.WriteByte('\n')
+	buf.Write(f.Format(nil, i))
+	buf.WriteByte('\n')
+
+	if strings.Count(strings.TrimSpace(buf.String()), "\n") != 1 {
+		t.Fatalf("not JSON lines: %s", buf.String())
+	}
+
+	dec := logs.NewJSONDecoder(&buf)
+
+	for n := 0; n < 2; n++ {
+		d, err := dec.Decode()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !d.Ts.Equal(i.Ts) || d.Level != i.Level || d.Prefix != i.Prefix ||
+			d.Message != i.Message || d.StackTrace() != i.StackTrace() {
+			t.Errorf("mismatch: %+v", d)
+		}
+
+		if d.Payload["rows"] != float64(3) {
+			t.Errorf("rows: %v", d.Payload["rows"])
+		}
+		if tags, ok := d.Payload["tags"].([]any); !ok || len(tags) != 2 {
+			t.Errorf("tags: %v", d.Payload["tags"])
+		}
+		for _, k := range []string{"ch", "fn", "cyclic"} {
+			s, _ := d.Payload[k].(string)
+			if !strings.HasPrefix(s, "!unserializable") {
+				t.Errorf("%s: %v", k, d.Payload[k])
+			}
+		}
+	}
+
+	_, err := dec.Decode()
+	if err != io.EOF {
+		t.Errorf("expected EOF, got %v", err)
+	}
+
+	// L'item è utilizzabile direttamente con encoding/json.
+	bb, err := json.Marshal(i)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var d logs.Item
+	if err = json.Unmarshal(bb, &d); err != nil {
+		t.Fatal(err)
+	}
+	if d.Message != i.Message {
+		t.Errorf("mismatch: %+v", d)
+	}
+}
@@ -0,0 +1,89 @@
+package test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/modulo-srl/sparalog"
+	"github.com/modulo-srl/sparalog/logs"
+	"github.com/modulo-srl/sparalog/writers"
+)
+
+type countingStringer struct {
+	calls *int
+}
+
+func (s countingStringer) String() string {
+	*s.calls++
+	return "expensive"
+}
+
+func TestLazy(t *testing.T) {
+	sparalog.InitUnitTest()
+
+	var last *logs.Item
+
+	w := writers.NewCallbackWriter(
+		func(item *logs.Item) error {
+			last = item
+			return nil
+		},
+	)
+	logs.ResetWriters(w)
+
+	sparalog.Start()
+	defer sparalog.Stop()
+
+	calls := 0
+	expensive := func() string {
+		calls++
+		return "expensive"
+	}
+
+	logger := logs.NewLogger("lazy")
+
+	// Livello debug mutato di default: nulla viene valutato.
+	if logs.Enabled(logs.DebugLevel) || logger.Enabled(logs.DebugLevel) {
+		t.Error("debug level enabled")
+	}
+
+	logs.DebugFunc(expensive)
+	logger.DebugFunc(expensive)
+	logger.Debug(logs.Lazy(expensive))
+	logger.Debugf("%s", countingStringer{&calls})
+
+	if calls != 0 || last != nil {
+		t.Errorf("muted messages evaluated %d times", calls)
+	}
+
+	// Livelli loggabili.
+	if !logger.Enabled(logs.InfoLevel) {
+		t.Error("info level not enabled")
+	}
+
+	logger.InfoFunc(expensive)
+	if calls != 1 || last.Message != "expensive" || last.Prefix != "lazy" {
+		t.Errorf("unexpected item: %+v", last)
+	}
+
+	logs.Info("value: ", logs.Lazy(expensive))
+	if calls != 2 || last.Message != "value: expensive" {
+		t.Errorf("unexpected item: %+v", last)
+	}
+
+	// Lo stacktrace parte dal chiamante.
+	logs.ErrorFunc(expensive)
+	if f := last.Stack.Frames[0].Function; !strings.HasSuffix(f, "TestLazy") {
+		t.Errorf("unexpected first frame: %s", f)
+	}
+
+	logger.LogFunc(logs.ErrorLevel, expensive)
+	if f := last.Stack.Frames[0].Function; !strings.HasSuffix(f, "TestLazy") {
+		t.Errorf("unexpected first frame: %s", f)
+	}
+
+	logger.Errorf("%d", 1)
+	if f := last.Stack.Frames[0].Function; !strings.HasSuffix(f, "TestLazy") || last.Message != "1" {
+		t.Errorf("unexpected item: %s %s", f, last.Message)
+	}
+}
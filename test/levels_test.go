@@ -0,0 +1,206 @@
+package test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/modulo-srl/sparalog"
+	"github.com/modulo-srl/sparalog/logs"
+	"github.com/modulo-srl/sparalog/writers"
+)
+
+func TestLevels(t *testing.T) {
+	sparalog.InitUnitTest()
+
+	var items []*logs.Item
+
+	w := writers.NewCallbackWriter(
+		func(item *logs.Item) error {
+			items = append(items, item)
+			return nil
+		},
+	)
+	logs.ResetWriters(w)
+
+	sparalog.Start()
+	defer sparalog.Stop()
+
+	logs.Critical("critical")
+	logs.Notice("notice")
+	logs.Trace("trace") // mutato di default
+
+	if len(items) != 2 {
+		t.Fatalf("expected 2 items, got %d", len(items))
+	}
+	if items[0].Level != logs.CriticalLevel || items[0].Stack == nil {
+		t.Errorf("critical item: %+v", items[0])
+	}
+	if items[1].Level != logs.NoticeLevel || items[1].Stack != nil {
+		t.Errorf("notice item: %+v", items[1])
+	}
+
+	s := items[0].ToString(false, false)
+	if s != "critical: critical" {
+		t.Errorf("unexpected text: %q", s)
+	}
+
+	// Le soglie seguono la gravità dei livelli e non il loro valore.
+	items = nil
+
+	err := logs.SetLoggersLevels("svc=notice")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Solo i livelli predefiniti: Levels include quelli registrati dagli altri test.
+	svc := logs.GetLogger("svc")
+	for _, level := range []logs.Level{
+		logs.ErrorLevel, logs.WarningLevel, logs.InfoLevel, logs.DebugLevel,
+		logs.TraceLevel, logs.NoticeLevel, logs.CriticalLevel,
+	} {
+		svc.Log(level, "msg")
+	}
+
+	var logged []string
+	for _, item := range items {
+		logged = append(logged, item.Level.String())
+	}
+	if s := strings.Join(logged, ","); s != "error,warning,notice,critical" {
+		t.Errorf("unexpected levels: %s", s)
+	}
+}
+
+func TestRegisterLevel(t *testing.T) {
+	sparalog.InitUnitTest()
+
+	var items []*logs.Item
+
+	w := writers.NewCallbackWriter(
+		func(item *logs.Item) error {
+			items = append(items, item)
+			return nil
+		},
+	)
+	logs.ResetWriters(w)
+
+	sparalog.Start()
+	defer sparalog.Stop()
+
+	t.Cleanup(logs.ResetLevels)
+
+	// Registrato dopo l'associazione del writer a tutti i livelli.
+	info := logs.LevelInfo{
+		Name:           "audit",
+		Icon:           "A",
+		Rank:           45,
+		SyslogSeverity: 5,
+		Critical:       true,
+	}
+	audit, err := logs.RegisterLevel(info)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := logs.RegisterLevel(info); err == nil {
+		t.Error("duplicate level registered")
+	}
+
+	// Rank e severità nulle renderebbero il livello grave quanto il fatal.
+	for _, info := range []logs.LevelInfo{
+		{Name: "norank", SyslogSeverity: 5},
+		{Name: "noseverity", Rank: 45},
+		{Name: "badseverity", Rank: 45, SyslogSeverity: 8},
+	} {
+		if _, err := logs.RegisterLevel(info); err == nil {
+			t.Errorf("invalid level %q registered", info.Name)
+		}
+	}
+
+	if audit.String() != "audit" || !audit.Info().Critical {
+		t.Errorf("unexpected level info: %+v", audit.Info())
+	}
+
+	logs.Logf(audit, "user %d logged in", 1)
+
+	if len(items) != 1 || items[0].Level != audit || items[0].Message != "user 1 logged in" {
+		t.Fatalf("unexpected items: %+v", items)
+	}
+
+	// Il nuovo livello riceve le riconfigurazioni.
+	ew := writers.NewCallbackWriter(func(item *logs.Item) error { return nil })
+	ew.SetName("audit")
+	logs.AddLevelWriter(audit, ew)
+
+	for _, info := range logs.Writers() {
+		if info.Name == "audit" && (len(info.Levels) != 1 || info.Levels[0] != audit) {
+			t.Errorf("audit writer: %+v", info)
+		}
+	}
+
+	logs.Mute(audit, true)
+	logs.Log(audit, "muted")
+	if len(items) != 1 {
+		t.Errorf("muted level logged")
+	}
+
+	logs.ResetLevels()
+
+	if len(logs.Levels) != int(logs.CriticalLevel)+1 || len(logs.CriticalLevels) != 4 {
+		t.Errorf("levels not reset: %v, %v", logs.Levels, logs.CriticalLevels)
+	}
+	if _, err := logs.ParseLevel("audit"); err == nil {
+		t.Error("audit level still registered")
+	}
+}
+
+func TestRegisterLevelConcurrent(t *testing.T) {
+	sparalog.InitUnitTest()
+
+	logger := logs.NewIsolatedLogger("levels", writers.NewCallbackWriter(func(item *logs.Item) error {
+		_ = item.Level.String()
+		return nil
+	}))
+	logger.Start()
+	defer logger.Stop()
+
+	stop := make(chan struct{})
+	started := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		close(started)
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+
+			logger.Warning("warning")
+			logs.ParseLevel("concurrent9")
+		}
+	}()
+
+	<-started
+
+	t.Cleanup(logs.ResetLevels)
+
+	for i := 0; i < 10; i++ {
+		_, err := logs.RegisterLevel(logs.LevelInfo{
+			Name:           "concurrent" + string(rune('0'+i)),
+			Rank:           80 + i,
+			SyslogSeverity: 7,
+		})
+		if err != nil {
+			t.Error(err)
+		}
+	}
+
+	// Estende l'instradamento ai nuovi livelli.
+	logger.AddLevelWriter(logs.WarningLevel, writers.NewCallbackWriter(func(item *logs.Item) error {
+		return nil
+	}))
+
+	close(stop)
+	<-done
+}
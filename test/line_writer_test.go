@@ -0,0 +1,134 @@
+package test
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"testing"
+
+	"github.com/modulo-srl/sparalog"
+	"github.com/modulo-srl/sparalog/logs"
+	"github.com/modulo-srl/sparalog/writers"
+)
+
+func TestLineWriter(t *testing.T) {
+	sparalog.InitUnitTest()
+
+	var items []*logs.Item
+
+	w := writers.NewCallbackWriter(
+		func(item *logs.Item) error {
+			items = append(items, item)
+			return nil
+		},
+	)
+	logs.ResetWriters(w)
+
+	sparalog.Start()
+	defer sparalog.Stop()
+
+	lw := logs.NewLineWriter(logs.NewLogger("lib"), logs.InfoLevel)
+	lw.DetectLevels(true)
+
+	fmt.Fprint(lw, "first line\nERROR: broken\n[warn] slow")
+	fmt.Fprint(lw, " query\r\n\n")
+	fmt.Fprint(lw, "[Debug] hidden\npartial")
+
+	if len(items) != 3 {
+		t.Fatalf("expected 3 items, got %d", len(items))
+	}
+
+	lw.Flush()
+
+	expected := []struct {
+		level logs.Level
+		msg   string
+	}{
+		{logs.InfoLevel, "first line"},
+		{logs.ErrorLevel, "broken"},
+		{logs.WarningLevel, "slow query"},
+		{logs.InfoLevel, "partial"},
+	}
+
+	if len(items) != len(expected) {
+		t.Fatalf("expected %d items, got %d", len(expected), len(items))
+	}
+
+	for i, e := range expected {
+		if items[i].Level != e.level || items[i].Message != e.msg || items[i].Prefix != "lib" {
+			t.Errorf("item %d: unexpected %s %q [%s]", i, logs.LevelsString[items[i].Level], items[i].Message, items[i].Prefix)
+		}
+	}
+}
+
+func TestRedirectStdLog(t *testing.T) {
+	sparalog.InitUnitTest()
+
+	var last *logs.Item
+
+	w := writers.NewCallbackWriter(
+		func(item *logs.Item) error {
+			last = item
+			return nil
+		},
+	)
+	logs.ResetWriters(w)
+
+	sparalog.Start()
+	defer sparalog.Stop()
+
+	restore := logs.RedirectStdLog(logs.InfoLevel)
+	defer restore()
+
+	log.Printf("value %d", 42)
+	if last == nil || last.Level != logs.InfoLevel || last.Message != "value 42" {
+		t.Fatalf("unexpected item: %+v", last)
+	}
+
+	log.Print("error: failure")
+	if last.Level != logs.ErrorLevel || last.Message != "failure" {
+		t.Fatalf("unexpected item: %+v", last)
+	}
+}
+
+func TestLineWriterCaller(t *testing.T) {
+	sparalog.InitUnitTest()
+
+	var last *logs.Item
+
+	w := writers.NewCallbackWriter(
+		func(item *logs.Item) error {
+			last = item
+			return nil
+		},
+	)
+	logs.ResetWriters(w)
+	logs.EnableLevelsCaller([]logs.Level{logs.InfoLevel})
+
+	sparalog.Start()
+	defer sparalog.Stop()
+
+	const fn = "sparalog/test.TestLineWriterCaller"
+
+	lw := logs.NewLineWriter(nil, logs.InfoLevel)
+
+	lw.Write([]byte("direct\npartial"))
+	if last == nil || last.Caller == nil || !strings.HasSuffix(last.Caller.Function, fn) {
+		t.Errorf("Write: unexpected item %+v", last)
+	}
+
+	last = nil
+	lw.Flush()
+	if last == nil || last.Caller == nil || !strings.HasSuffix(last.Caller.Function, fn) {
+		t.Errorf("Flush: unexpected item %+v", last)
+	}
+
+	restore := logs.RedirectStdLog(logs.InfoLevel)
+	defer restore()
+
+	last = nil
+	log.Printf("value %d", 42)
+	if last == nil || last.Caller == nil || !strings.HasSuffix(last.Caller.Function, fn) {
+		t.Errorf("log.Printf: unexpected item %+v", last)
+	}
+}
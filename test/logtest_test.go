@@ -0,0 +1,89 @@
+package test
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/modulo-srl/sparalog"
+	"github.com/modulo-srl/sparalog/logs"
+	"github.com/modulo-srl/sparalog/logtest"
+	"github.com/modulo-srl/sparalog/writers"
+)
+
+// testing.TB che registra i fallimenti senza far fallire il test.
+type fakeTB struct {
+	testing.TB
+
+	errors []string
+}
+
+func (tb *fakeTB) Helper() {}
+
+func (tb *fakeTB) Errorf(format string, args ...any) {
+	tb.errors = append(tb.errors, fmt.Sprintf(format, args...))
+}
+
+func TestLogtest(t *testing.T) {
+	sparalog.InitUnitTest()
+
+	r := logtest.Install(t)
+
+	// Writer asincrono che fallisce: il feedback raggiunge il recorder (writer di default).
+	wa := writers.NewCallbackAsyncWriter(
+		func(item *logs.Item) error {
+			return errors.New("async failure")
+		},
+	)
+	logs.AddWriter(wa)
+
+	sparalog.Start()
+	defer sparalog.Stop()
+
+	logs.NewLogger("db").With("rows", 3).Info("query done")
+
+	item := logtest.AssertLogged(t, logs.InfoLevel, "query",
+		logtest.Prefix("db"), logtest.Payload("rows", 3), logtest.HasPayload("rows"))
+	if item == nil || item.Message != "query done" {
+		t.Errorf("unexpected item: %+v", item)
+	}
+
+	logtest.AssertLogged(t, logs.ErrorLevel, "async failure")
+	logtest.AssertLogged(t, logtest.AnyLevel, "failure")
+	logtest.AssertNotLogged(t, logs.WarningLevel, "")
+
+	// Asserzioni fallite.
+	ftb := &fakeTB{TB: t}
+
+	r.AssertLogged(ftb, logs.InfoLevel, "query", logtest.Payload("rows", 4))
+	r.AssertLogged(ftb, logs.DebugLevel, "query")
+	r.AssertNotLogged(ftb, logs.InfoLevel, "query")
+
+	if len(ftb.errors) != 3 {
+		t.Fatalf("unexpected failures: %v", ftb.errors)
+	}
+
+	if !strings.Contains(ftb.errors[0], "query done") {
+		t.Errorf("logged items not reported: %s", ftb.errors[0])
+	}
+
+	r.Reset()
+	if len(r.Items()) != 0 {
+		t.Error("items not reset")
+	}
+}
+
+func TestLogtestLogger(t *testing.T) {
+	logger, r := logtest.NewLogger(t, "isolated")
+
+	logger.Warning("careful")
+	logger.Debug("muted")
+
+	r.AssertLogged(t, logs.WarningLevel, "careful", logtest.Prefix("isolated"))
+	r.AssertNotLogged(t, logs.DebugLevel, "muted")
+
+	if n := len(r.Find(logtest.AnyLevel, "")); n != 1 {
+		t.Errorf("%d items recorded", n)
+	}
+}
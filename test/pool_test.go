@@ -0,0 +1,230 @@
+package test
+
+import (
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/modulo-srl/sparalog"
+	"github.com/modulo-srl/sparalog/logs"
+	"github.com/modulo-srl/sparalog/logtest"
+	"github.com/modulo-srl/sparalog/writers"
+)
+
+// Writer sincrono che formatta gli item scartandoli.
+type discardWriter struct {
+	writers.Writer
+}
+
+func (w *discardWriter) PoolSafe() {}
+
+func (w *discardWriter) Write(item *logs.Item) {
+	w.WriteFormatted(io.Discard, item)
+}
+
+// Writer asincrono che verifica gli item solo dopo il ritorno di Write().
+type checkAsyncWriter struct {
+	writers.Writer
+
+	received []string
+}
+
+func (w *checkAsyncWriter) Start() error {
+	w.StartQueue(10, func(item *logs.Item) error {
+		w.received = append(w.received, item.Message)
+		return nil
+	})
+	return nil
+}
+
+func (w *checkAsyncWriter) Stop() {
+	w.StopQueue(3)
+}
+
+func (w *checkAsyncWriter) PoolSafe() {}
+
+func (w *checkAsyncWriter) Write(item *logs.Item) {
+	w.Enqueue(item)
+}
+
+// Writer di terze parti che conserva gli item senza acquisirli.
+type keepWriter struct {
+	writers.Writer
+
+	items []*logs.Item
+}
+
+func (w *keepWriter) Write(item *logs.Item) {
+	w.items = append(w.items, item)
+}
+
+func newDiscardLogger() *logs.Logger {
+	w := &discardWriter{}
+	w.SetFormatter(logs.NewTextFormatter())
+
+	return logs.NewIsolatedLogger("bench", w)
+}
+
+func TestPoolAllocs(t *testing.T) {
+	sparalog.InitUnitTest()
+
+	logger := newDiscardLogger()
+	logger.Start()
+	defer logger.Stop()
+
+	allocs := testing.AllocsPerRun(1000, func() {
+		logger.Info("message")
+	})
+	if allocs != 0 {
+		t.Errorf("Info: %v allocs per call", allocs)
+	}
+
+	allocs = testing.AllocsPerRun(1000, func() {
+		logger.Debug("muted")
+	})
+	if allocs != 0 {
+		t.Errorf("muted Debug: %v allocs per call", allocs)
+	}
+}
+
+func TestPoolAsyncWriter(t *testing.T) {
+	sparalog.InitUnitTest()
+
+	w := &checkAsyncWriter{}
+	logger := logs.NewIsolatedLogger("pool", w)
+	logger.Start()
+
+	const n = 1000
+	for i := 0; i < n; i++ {
+		logger.Info(fmt.Sprint(i))
+	}
+
+	logger.Stop()
+
+	if len(w.received) != n {
+		t.Fatalf("received %d items", len(w.received))
+	}
+
+	// Gli item non vengono riciclati prima di essere processati dal writer asincrono.
+	for i, msg := range w.received {
+		if msg != fmt.Sprint(i) {
+			t.Fatalf("item %d recycled: %q", i, msg)
+		}
+	}
+}
+
+func TestPoolUnsafeWriter(t *testing.T) {
+	sparalog.InitUnitTest()
+
+	w := &keepWriter{}
+	logger := logs.NewIsolatedLogger("pool", w)
+	logger.Start()
+	defer logger.Stop()
+
+	const n = 1000
+	for i := 0; i < n; i++ {
+		logger.Info(fmt.Sprint(i))
+	}
+
+	// Il writer non implementa logs.PoolSafeWriter: gli item non vengono riciclati.
+	for i, item := range w.items {
+		if item.Message != fmt.Sprint(i) {
+			t.Fatalf("item %d recycled: %q", i, item.Message)
+		}
+	}
+}
+
+func BenchmarkInfo(b *testing.B) {
+	logger := newDiscardLogger()
+	logger.Start()
+	defer logger.Stop()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		logger.Info("message")
+	}
+}
+
+func BenchmarkInfof(b *testing.B) {
+	logger := newDiscardLogger()
+	logger.Start()
+	defer logger.Stop()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		logger.Infof("message %d", i)
+	}
+}
+
+func BenchmarkInfoPayload(b *testing.B) {
+	logger := newDiscardLogger().With("user", "john", "id", 42)
+	logger.Start()
+	defer logger.Stop()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		logger.Info("message")
+	}
+}
+
+func BenchmarkMuted(b *testing.B) {
+	logger := newDiscardLogger()
+	logger.Start()
+	defer logger.Stop()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		logger.Debug("message")
+	}
+}
+
+func BenchmarkInfoParallel(b *testing.B) {
+	logger := newDiscardLogger()
+	logger.Start()
+	defer logger.Stop()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			logger.Info("message")
+		}
+	})
+}
+
+func TestPoolRetainingWriters(t *testing.T) {
+	sparalog.InitUnitTest()
+
+	logger, r := logtest.NewLogger(t, "pool")
+
+	var kept []*logs.Item
+	logger.AddWriter(writers.NewCallbackWriter(func(item *logs.Item) error {
+		kept = append(kept, item)
+		return nil
+	}))
+
+	const n = 200
+	for i := 0; i < n; i++ {
+		logger.Info(fmt.Sprint(i))
+	}
+
+	// Recorder e callback writer conservano gli item: non vengono riciclati.
+	items := r.Items()
+	if len(items) != n || len(kept) != n {
+		t.Fatalf("recorded %d, kept %d items", len(items), len(kept))
+	}
+	for i := 0; i < n; i++ {
+		if items[i].Message != fmt.Sprint(i) || kept[i].Message != fmt.Sprint(i) {
+			t.Fatalf("item %d recycled: %q, %q", i, items[i].Message, kept[i].Message)
+		}
+	}
+}
@@ -0,0 +1,238 @@
+package test
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/modulo-srl/sparalog"
+	"github.com/modulo-srl/sparalog/logs"
+	"github.com/modulo-srl/sparalog/writers"
+)
+
+func TestQueuePolicies(t *testing.T) {
+	sparalog.InitUnitTest()
+
+	tests := []struct {
+		policy    writers.QueuePolicy
+		levels    []logs.Level
+		processed string
+		dropped   int
+	}{
+		{writers.QueueDropNewest, nil, "0 1 2", 3},
+		{writers.QueueDropOldest, nil, "0 4 5", 3},
+		{writers.QueueBlockTimeout, nil, "0 1 2", 3},
+		{writers.QueueDropNonCritical, []logs.Level{logs.InfoLevel, logs.InfoLevel, logs.ErrorLevel}, "0 1 2 3", 2},
+	}
+
+	for _, tt := range tests {
+		var mu sync.Mutex
+		var processed []string
+
+		entered := make(chan struct{}, 10)
+		release := make(chan struct{})
+
+		w := writers.NewCallbackAsyncWriter(
+			func(item *logs.Item) error {
+				entered <- struct{}{}
+				<-release
+
+				mu.Lock()
+				defer mu.Unlock()
+				processed = append(processed, item.Message)
+				return nil
+			},
+		)
+		w.SetQueueOptions(writers.QueueOptions{
+			Size:           2,
+			Policy:         tt.policy,
+			Timeout:        5 * time.Millisecond,
+			ReportInterval: 5 * time.Millisecond,
+		})
+
+		feedback := make(chan *logs.Item, 10)
+		w.SetFeedbackChan(feedback)
+
+		w.Start()
+
+		// Il primo item blocca il worker, i successivi riempiono la coda.
+		w.Write(logs.NewItem(logs.InfoLevel, "0"))
+		<-entered
+
+		var wg sync.WaitGroup
+		for n := 1; n < 6; n++ {
+			level := logs.InfoLevel
+			if n-1 < len(tt.levels) {
+				level = tt.levels[n-1]
+			}
+
+			item := logs.NewItem(level, fmt.Sprint(n))
+
+			if tt.policy == writers.QueueDropNonCritical && level == logs.ErrorLevel {
+				// Gli item critici bloccano finché la coda non si libera.
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					w.Write(item)
+				}()
+				time.Sleep(5 * time.Millisecond)
+				continue
+			}
+
+			w.Write(item)
+		}
+
+		go func() {
+			for range entered {
+			}
+		}()
+		close(release)
+		wg.Wait()
+
+		select {
+		case item := <-feedback:
+			expected := fmt.Sprintf("queue full: %d items dropped", tt.dropped)
+			if !strings.HasSuffix(item.Message, expected) {
+				t.Errorf("policy %d: unexpected feedback %q", tt.policy, item.Message)
+			}
+		case <-time.After(time.Second):
+			t.Errorf("policy %d: dropped items not reported", tt.policy)
+		}
+
+		w.Stop()
+		close(entered)
+
+		mu.Lock()
+		if strings.Join(processed, " ") != tt.processed {
+			t.Errorf("policy %d: processed %v, expected %s", tt.policy, processed, tt.processed)
+		}
+		mu.Unlock()
+	}
+}
+
+func TestQueueRestart(t *testing.T) {
+	sparalog.InitUnitTest()
+
+	var mu sync.Mutex
+	var processed []string
+
+	w := writers.NewCallbackAsyncWriter(
+		func(item *logs.Item) error {
+			mu.Lock()
+			defer mu.Unlock()
+			processed = append(processed, item.Message)
+			return nil
+		},
+	)
+	logs.ResetWriters(w)
+
+	// Stop e riavvio dello stesso writer, sia con il dispatcher che rimuovendolo e riassociandolo.
+	sparalog.Start()
+	logs.Info("first")
+	sparalog.Stop()
+
+	sparalog.InitUnitTest()
+	logs.ResetWriters(w)
+	sparalog.Start()
+	defer sparalog.Stop()
+
+	logs.Info("second")
+
+	logs.AddWriter(writers.NewCallbackWriter(func(*logs.Item) error { return nil }))
+	if err := logs.RemoveWriter(w.ID()); err != nil {
+		t.Fatal(err)
+	}
+	if err := logs.AddWriter(w); err != nil {
+		t.Fatal(err)
+	}
+
+	logs.Info("third")
+
+	if err := logs.Flush(time.Second); err != nil {
+		t.Fatal(err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if s := strings.Join(processed, " "); s != "first second third" {
+		t.Errorf("processed: %s", s)
+	}
+}
+
+func TestQueueStopTimeout(t *testing.T) {
+	sparalog.InitUnitTest()
+
+	var mu sync.Mutex
+	var processed []string
+
+	entered := make(chan struct{}, 10)
+	release := make(chan struct{})
+
+	w := writers.NewCallbackAsyncWriter(
+		func(item *logs.Item) error {
+			if item.Message == "0" {
+				entered <- struct{}{}
+				<-release
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			processed = append(processed, item.Message)
+			return nil
+		},
+	)
+
+	feedback := make(chan *logs.Item, 10)
+	w.SetFeedbackChan(feedback)
+
+	w.Start()
+
+	// Il primo item blocca il worker oltre il timeout dello stop.
+	for n := 0; n < 3; n++ {
+		w.Write(logs.NewItem(logs.InfoLevel, fmt.Sprint(n)))
+	}
+	<-entered
+
+	w.Stop()
+
+	select {
+	case item := <-feedback:
+		if !strings.HasSuffix(item.Message, "queue stopped: 0 items dropped, 3 not delivered") {
+			t.Errorf("unexpected feedback %q", item.Message)
+		}
+	default:
+		t.Error("undelivered items not reported")
+	}
+
+	// Il worker distaccato non consuma la nuova coda, e consegna i propri item rimanenti.
+	w.SetFeedbackChan(feedback)
+	w.Start()
+	close(release)
+
+	w.Write(logs.NewItem(logs.InfoLevel, "3"))
+	w.Stop()
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		mu.Lock()
+		n := len(processed)
+		mu.Unlock()
+
+		if n == 4 || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	sort.Strings(processed)
+	if s := strings.Join(processed, " "); s != "0 1 2 3" {
+		t.Errorf("processed: %s", s)
+	}
+}
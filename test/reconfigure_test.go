@@ -0,0 +1,157 @@
+package test
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/modulo-srl/sparalog"
+	"github.com/modulo-srl/sparalog/logs"
+	"github.com/modulo-srl/sparalog/writers"
+)
+
+func TestRuntimeReconfiguration(t *testing.T) {
+	sparalog.InitUnitTest()
+
+	base := writers.NewCallbackWriter(
+		func(item *logs.Item) error {
+			return nil
+		},
+	)
+	logs.ResetWriters(base)
+
+	sparalog.Start()
+	defer sparalog.Stop()
+
+	// Logga in continuo mentre i writer vengono riconfigurati.
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+
+	for n := 0; n < 4; n++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+					logs.Info("in flight")
+				}
+			}
+		}()
+	}
+
+	var received atomic.Int32
+
+	for n := 0; n < 20; n++ {
+		// Aggiunto dopo lo Start(): deve essere avviato automaticamente.
+		aw := writers.NewCallbackAsyncWriter(
+			func(item *logs.Item) error {
+				received.Add(1)
+				return nil
+			},
+		)
+
+		err := logs.AddWriter(aw)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		time.Sleep(time.Millisecond)
+
+		// Rimuove aw, che viene stoppato dopo averne svuotato la coda.
+		err = logs.ResetWriters(base)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	count := received.Load()
+
+	time.Sleep(10 * time.Millisecond)
+
+	close(stop)
+	wg.Wait()
+
+	if count == 0 {
+		t.Error("added writers never received items")
+	}
+
+	if received.Load() != count {
+		t.Error("removed writers still receiving items")
+	}
+}
+
+// Writer che rileva le scritture ricevute dopo lo Stop().
+type stopCheckWriter struct {
+	writers.Writer
+
+	stopped atomic.Bool
+	late    atomic.Int64
+}
+
+func (w *stopCheckWriter) PoolSafe() {}
+
+func (w *stopCheckWriter) Write(item *logs.Item) {
+	// Allarga la finestra in cui lo Stop() può sovrapporsi alla scrittura.
+	time.Sleep(10 * time.Microsecond)
+
+	if w.stopped.Load() {
+		w.late.Add(1)
+	}
+}
+
+func (w *stopCheckWriter) Stop() {
+	w.stopped.Store(true)
+}
+
+func TestRemoveWriterInFlight(t *testing.T) {
+	sparalog.InitUnitTest()
+
+	logger := logs.NewIsolatedLogger("inflight", writers.NewCallbackWriter(func(item *logs.Item) error {
+		return nil
+	}))
+	logger.Start()
+	defer logger.Stop()
+
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+
+	for n := 0; n < 2; n++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+					logger.Info("message")
+				}
+			}
+		}()
+	}
+
+	var ww []*stopCheckWriter
+	for n := 0; n < 20; n++ {
+		w := &stopCheckWriter{}
+		ww = append(ww, w)
+
+		logger.AddWriter(w)
+		time.Sleep(100 * time.Microsecond)
+		logger.RemoveWriter(w.ID())
+	}
+
+	close(stop)
+	wg.Wait()
+
+	for i, w := range ww {
+		if n := w.late.Load(); n > 0 {
+			t.Errorf("writer %d: %d writes after Stop()", i, n)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/modulo-srl/sparalog"
+	"github.com/modulo-srl/sparalog/logs"
+	"github.com/modulo-srl/sparalog/writers"
+)
+
+func TestLoggersRegistry(t *testing.T) {
+	sparalog.InitUnitTest()
+
+	logged := make(map[string][]logs.Level)
+
+	w := writers.NewCallbackWriter(
+		func(item *logs.Item) error {
+			logged[item.Prefix] = append(logged[item.Prefix], item.Level)
+			return nil
+		},
+	)
+	logs.ResetWriters(w)
+
+	sparalog.Start()
+	defer sparalog.Stop()
+
+	pool := logs.GetLogger("db/pool")
+	if logs.GetLogger("db/pool") != pool {
+		t.Fatal("GetLogger returned a different logger for the same name")
+	}
+
+	http := logs.GetLogger("http")
+	other := logs.GetLogger("other")
+
+	ll := logs.Loggers()
+	if len(ll) != 3 || ll[0].Name() != "db/pool" || ll[1].Name() != "http" || ll[2].Name() != "other" {
+		t.Fatalf("unexpected loggers: %v", ll)
+	}
+
+	err := logs.SetLoggersLevels("db/*=debug, http=warning")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, l := range []*logs.Logger{pool, http, other} {
+		l.Debug("debug")
+		l.Info("info")
+		l.Warning("warning")
+	}
+
+	// Creato dopo le regole: gli vengono applicate comunque.
+	logs.GetLogger("db/cache").Debug("debug")
+
+	expected := map[string]int{
+		"db/pool":  3, // il debug è smutato dalla regola
+		"http":     1, // solo warning
+		"other":    2, // regole globali: debug mutato
+		"db/cache": 1,
+	}
+	for name, n := range expected {
+		if len(logged[name]) != n {
+			t.Errorf("%s: expected %d items, got %v", name, n, logged[name])
+		}
+	}
+
+	err = logs.SetLoggersLevels("db/*=verbose")
+	if err == nil {
+		t.Error("expected error for unknown level")
+	}
+
+	// Rimuove le regole.
+	logs.SetLoggersLevels("")
+	pool.Debug("debug")
+
+	if len(logged["db/pool"]) != 3 {
+		t.Errorf("rules not reset: %v", logged["db/pool"])
+	}
+}
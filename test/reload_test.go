@@ -0,0 +1,128 @@
+package test
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/modulo-srl/sparalog"
+	"github.com/modulo-srl/sparalog/logs"
+	"github.com/modulo-srl/sparalog/writers"
+)
+
+func TestReload(t *testing.T) {
+	sparalog.InitUnitTest()
+
+	received := map[string][]string{}
+
+	sparalog.RegisterWriterType("recorder", func(params json.RawMessage) (logs.Writer, error) {
+		var p struct {
+			Key string `json:"key"`
+		}
+
+		err := sparalog.DecodeWriterParams(params, &p)
+		if err != nil {
+			return nil, err
+		}
+
+		return writers.NewCallbackWriter(func(item *logs.Item) error {
+			received[p.Key] = append(received[p.Key], item.Message)
+			return nil
+		}), nil
+	})
+
+	filename := filepath.Join(t.TempDir(), "sparalog.json")
+
+	writeConfig := func(cfg string) {
+		err := os.WriteFile(filename, []byte(cfg), 0666)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	writerIDs := func() map[string]string {
+		ids := map[string]string{}
+		for _, info := range logs.Writers() {
+			ids[info.Name] = info.ID
+		}
+		return ids
+	}
+
+	if sparalog.Reload() == nil {
+		t.Error("reload without configuration file")
+	}
+
+	writeConfig(`{
+		"writers": {
+			"main": {"type": "recorder", "params": {"key": "main"}},
+			"errors": {"type": "recorder", "levels": ["error"], "params": {"key": "errors"}},
+			"old": {"type": "recorder", "params": {"key": "old"}}
+		},
+		"default": "main"
+	}`)
+
+	err := sparalog.ConfigureFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sparalog.Start()
+	defer sparalog.Stop()
+
+	before := writerIDs()
+
+	logs.Warning("first")
+
+	writeConfig(`{
+		"writers": {
+			"main": {"type": "recorder", "params": {"key": "main"}},
+			"errors": {"type": "recorder", "levels": ["error", "warning"], "params": {"key": "errors"}},
+			"new": {"type": "recorder", "levels": ["warning"], "params": {"key": "new"}}
+		},
+		"default": "main",
+		"mute": []
+	}`)
+
+	err = sparalog.Reload()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	after := writerIDs()
+
+	// I writer invariati a meno dei livelli vengono mantenuti.
+	if after["main"] != before["main"] || after["errors"] != before["errors"] {
+		t.Errorf("unchanged writers replaced: %v -> %v", before, after)
+	}
+	if _, ok := after["old"]; ok {
+		t.Error("removed writer still associated")
+	}
+
+	logs.Warning("second")
+	logs.Debug("debug")
+
+	expected := map[string]string{
+		"main":   "first,second,debug",
+		"errors": "second",
+		"old":    "first",
+		"new":    "second",
+	}
+	for key, msgs := range expected {
+		if s := strings.Join(received[key], ","); s != msgs {
+			t.Errorf("writer %s: expected %q, got %q", key, msgs, s)
+		}
+	}
+
+	// Una configurazione non valida lascia in uso quella corrente.
+	writeConfig(`{"writers": {"main": {"type": "nope"}}, "default": "main"}`)
+
+	if sparalog.Reload() == nil {
+		t.Error("invalid configuration applied")
+	}
+
+	if ids := writerIDs(); ids["main"] != after["main"] || ids["new"] != after["new"] {
+		t.Errorf("writers changed by invalid configuration: %v", ids)
+	}
+}
@@ -0,0 +1,90 @@
+package test
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/modulo-srl/sparalog"
+	"github.com/modulo-srl/sparalog/logs"
+	"github.com/modulo-srl/sparalog/writers"
+)
+
+func TestSlogHandler(t *testing.T) {
+	sparalog.InitUnitTest()
+
+	var last *logs.Item
+
+	w := writers.NewCallbackWriter(
+		func(item *logs.Item) error {
+			last = item
+			return nil
+		},
+	)
+	logs.ResetWriters(w)
+
+	sparalog.Start()
+	defer sparalog.Stop()
+
+	logger := logs.NewLogger("slog")
+	logger.SetPayload("component", "api")
+
+	sl := slog.New(logs.NewSlogHandler(logger))
+
+	sl.With("request_id", "req-1").WithGroup("http").Warn("handled",
+		"status", 200, slog.Group("client", "ip", "127.0.0.1"))
+
+	if last == nil || last.Level != logs.WarningLevel || last.Prefix != "slog" || last.Message != "handled" {
+		t.Fatalf("unexpected item: %+v", last)
+	}
+
+	expected := map[string]any{
+		"component":      "api",
+		"request_id":     "req-1",
+		"http.status":    int64(200),
+		"http.client.ip": "127.0.0.1",
+	}
+	for k, v := range expected {
+		if last.Payload[k] != v {
+			t.Errorf("payload %s: expected %v, got %v", k, v, last.Payload[k])
+		}
+	}
+
+	// Debug è disabilitato di default.
+	last = nil
+	sl.Debug("hidden")
+	if last != nil {
+		t.Errorf("debug item dispatched: %+v", last)
+	}
+
+	// Lo stacktrace parte dalla chiamata slog.
+	sl.Error("failed")
+	if last == nil || last.Stack == nil || len(last.Stack.Frames) == 0 {
+		t.Fatalf("missing stacktrace: %+v", last)
+	}
+	if f := last.Stack.Frames[0].Function; !strings.HasSuffix(f, "TestSlogHandler") {
+		t.Errorf("unexpected first frame: %s", f)
+	}
+}
+
+func TestSlogWriter(t *testing.T) {
+	var buf bytes.Buffer
+
+	h := slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})
+
+	w := writers.NewSlogWriter(h)
+	logger := logs.NewIsolatedLogger("prefix", w)
+	logger.SetPayload("key", "value")
+	logger.Start()
+
+	logger.Info("message")
+	logger.Stop()
+
+	out := buf.String()
+	for _, s := range []string{"level=INFO", "msg=message", "prefix=prefix", "key=value"} {
+		if !strings.Contains(out, s) {
+			t.Errorf("%q not found in %q", s, out)
+		}
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"github.com/modulo-srl/sparalog"
+	"github.com/modulo-srl/sparalog/env"
 	"github.com/modulo-srl/sparalog/logs"
 	"github.com/modulo-srl/sparalog/writers"
 )
@@ -57,16 +58,79 @@ func TestStacktraceLogger(t *testing.T) {
 
 // check if the stacktrace starts with current testing function.
 func checkStacktrace(item *logs.Item, t *testing.T) {
-	lines := strings.Split(item.StackTrace, "\n")
+	lines := strings.Split(item.StackTrace(), "\n")
 
 	if len(lines) < 2 {
-		t.Errorf("No stacktrace for \"%s\"\n%s", item.Message, item.StackTrace)
+		t.Errorf("No stacktrace for \"%s\"\n%s", item.Message, item.StackTrace())
 		return
 	}
 
 	i := strings.Index(lines[1], "test.TestStacktraceLogger")
 
 	if i < 0 {
-		t.Errorf("Invalid stacktrace for \"%s\"\n%s", item.Message, item.StackTrace)
+		t.Errorf("Invalid stacktrace for \"%s\"\n%s", item.Message, item.StackTrace())
+	}
+}
+
+func TestStackFrames(t *testing.T) {
+	sparalog.InitUnitTest()
+
+	i := logs.NewItem(logs.ErrorLevel, "frames")
+
+	if i.Stack == nil || len(i.Stack.Frames) == 0 {
+		t.Fatal("no stack frames")
+	}
+
+	f := i.Stack.Frames[0]
+	if !strings.HasSuffix(f.Function, "test.TestStackFrames") ||
+		!strings.HasSuffix(f.File, "stacktrace_test.go") || f.Line == 0 {
+		t.Errorf("invalid top frame: %+v", f)
+	}
+
+	if i.Stack.GoroutineID == "" {
+		t.Error("no goroutine ID")
+	}
+
+	// Il crop mantiene solo frame interi.
+	full := i.Stack.String()
+	cropped := i.Stack.Crop(len(full) - 1)
+	if len(cropped) >= len(full) || !strings.HasPrefix(full, cropped) ||
+		strings.Count(cropped, "\n")%2 != 0 {
+		t.Errorf("invalid crop:\n%s", cropped)
+	}
+}
+
+func TestParseStack(t *testing.T) {
+	output := "goroutine 6 [running]:\n" +
+		"main.main.func1()\n" +
+		"\t/src/main.go:30 +0x1d\n" +
+		"created by main.main in goroutine 1\n" +
+		"\t/src/main.go:28 +0x25\n" +
+		"\n" +
+		"goroutine 1 [sleep]:\n" +
+		"time.Sleep(0x3b9aca00)\n" +
+		"\t/go/src/runtime/time.go:195 +0x135\n"
+
+	s := env.ParseStack(output)
+	if s == nil {
+		t.Fatal("stack not parsed")
+	}
+
+	if s.GoroutineID != "6" || len(s.Frames) != 2 {
+		t.Fatalf("invalid stack: %+v", s)
+	}
+
+	expected := env.Frame{Function: "main.main.func1()", File: "/src/main.go", Line: 30}
+	if s.Frames[0] != expected {
+		t.Errorf("invalid frame: %+v", s.Frames[0])
+	}
+}
+
+func TestParseStackMalformed(t *testing.T) {
+	for _, output := range []string{"goroutine \t\nmain.main()\n\t/src/main.go:30", "goroutine", "panic: nope"} {
+		s := env.ParseStack(output)
+		if s != nil && s.GoroutineID != "" {
+			t.Errorf("%q: unexpected goroutine %q", output, s.GoroutineID)
+		}
 	}
 }
@@ -0,0 +1,94 @@
+package test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/modulo-srl/sparalog"
+	"github.com/modulo-srl/sparalog/logs"
+	"github.com/modulo-srl/sparalog/logtest"
+)
+
+func TestSystem(t *testing.T) {
+	for i := 0; i < 4; i++ {
+		i := i
+
+		t.Run(fmt.Sprint(i), func(t *testing.T) {
+			t.Parallel()
+
+			s, r := logtest.NewSystem(t)
+
+			// Configurazione indipendente per sistema.
+			if i%2 == 0 {
+				s.EnableLevelsStackTrace(nil)
+				s.Mute(logs.DebugLevel, false)
+			}
+
+			s.Logger().Error("error ", i)
+			s.Logger().Debug("debug ", i)
+			s.GetLogger("db").Info("query ", i)
+
+			item := r.AssertLogged(t, logs.ErrorLevel, fmt.Sprint("error ", i))
+			if i%2 == 0 {
+				r.AssertLogged(t, logs.DebugLevel, fmt.Sprint("debug ", i))
+
+				if item != nil && item.Stack != nil {
+					t.Error("unexpected stacktrace")
+				}
+			} else {
+				r.AssertNotLogged(t, logs.DebugLevel, "")
+
+				// Lo stacktrace parte dal chiamante, anche usando direttamente il logger del sistema.
+				if item == nil || item.Stack == nil ||
+					!strings.HasPrefix(item.Stack.Frames[0].Function, "github.com/modulo-srl/sparalog/test.TestSystem") {
+					t.Errorf("unexpected stacktrace: %+v", item)
+				}
+			}
+
+			r.AssertLogged(t, logs.InfoLevel, fmt.Sprint("query ", i), logtest.Prefix("db"))
+
+			if n := len(r.Items()); n != 2+(i+1)%2 {
+				t.Errorf("%d items recorded", n)
+			}
+
+			if ll := s.Loggers(); len(ll) != 1 || ll[0].Name() != "db" {
+				t.Errorf("unexpected loggers: %v", ll)
+			}
+		})
+	}
+}
+
+func TestDefaultSystem(t *testing.T) {
+	sparalog.InitUnitTest()
+
+	r := logtest.Install(t)
+
+	sparalog.Start()
+	defer sparalog.Stop()
+
+	s := sparalog.NewSystem()
+	s.ResetWriters(logtest.NewRecorder(nil))
+	s.Start()
+	defer s.Stop()
+
+	// Il sistema indipendente non riceve le loggate del sistema di default, e viceversa.
+	s.Logger().Warning("system")
+	logs.Warning("default")
+
+	r.AssertLogged(t, logs.WarningLevel, "default")
+	r.AssertNotLogged(t, logs.WarningLevel, "system")
+
+	if logs.DefaultSystem() == s {
+		t.Error("default system replaced")
+	}
+
+	// Il logger di default, anche ottenuto dal contesto, genera lo stacktrace dal chiamante.
+	logs.FromContext(context.Background()).Error("from context")
+
+	item := r.AssertLogged(t, logs.ErrorLevel, "from context")
+	if item == nil || item.Stack == nil || !strings.HasSuffix(item.Stack.Frames[0].Function, "TestDefaultSystem") {
+		t.Errorf("unexpected stacktrace: %+v", item)
+	}
+}
@@ -0,0 +1,191 @@
+package test
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/modulo-srl/sparalog"
+	"github.com/modulo-srl/sparalog/logs"
+	"github.com/modulo-srl/sparalog/writers"
+)
+
+func TestDedup(t *testing.T) {
+	sparalog.InitUnitTest()
+
+	var mu sync.Mutex
+	var items []*logs.Item
+
+	w := writers.NewCallbackWriter(
+		func(item *logs.Item) error {
+			mu.Lock()
+			defer mu.Unlock()
+			items = append(items, item)
+			return nil
+		},
+	)
+	logs.ResetWriters(w)
+
+	sparalog.Start()
+	defer sparalog.Stop()
+
+	logs.SetDedupWindow(50 * time.Millisecond)
+
+	for n := 0; n < 10; n++ {
+		logs.Error("tight loop")
+	}
+	logs.Error("other")
+
+	mu.Lock()
+	if len(items) != 2 {
+		t.Errorf("expected 2 items, got %d", len(items))
+	}
+	mu.Unlock()
+
+	time.Sleep(100 * time.Millisecond)
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if len(items) != 3 {
+		t.Fatalf("expected 3 items, got %d", len(items))
+	}
+
+	summary := items[2]
+	if summary.Message != "tight loop (repeated 9 times)" || summary.Payload["repeated"] != 9 {
+		t.Errorf("invalid summary: %s %v", summary.Message, summary.Payload)
+	}
+}
+
+func TestDedupSummary(t *testing.T) {
+	sparalog.InitUnitTest()
+
+	var mu sync.Mutex
+	var items []*logs.Item
+
+	w := writers.NewCallbackWriter(
+		func(item *logs.Item) error {
+			mu.Lock()
+			defer mu.Unlock()
+			items = append(items, item)
+			return nil
+		},
+	)
+	logs.ResetWriters(w)
+	logs.EnableLevelsCaller([]logs.Level{logs.WarningLevel})
+
+	sparalog.Start()
+	defer sparalog.Stop()
+
+	logs.SetDedupWindow(50 * time.Millisecond)
+
+	logger := logs.NewLogger("db").With("table", "users")
+	for n := 0; n < 3; n++ {
+		logger.Warning("slow query")
+	}
+	for n := 0; n < 3; n++ {
+		logs.Info("muted later")
+	}
+
+	// Livello mutato ma abilitato dalle regole del logger: il riepilogo viene inviato.
+	logs.SetLoggersLevels("trace=debug")
+	defer logs.SetLoggersLevels("")
+	for n := 0; n < 3; n++ {
+		logs.GetLogger("trace").Debug("enabled by rule")
+	}
+
+	// Il riepilogo di un livello mutato nel frattempo viene scartato.
+	logs.Mute(logs.InfoLevel, true)
+
+	time.Sleep(100 * time.Millisecond)
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if len(items) != 5 {
+		t.Fatalf("expected 5 items, got %d", len(items))
+	}
+
+	var summary *logs.Item
+	rule := false
+	for _, item := range items[3:] {
+		switch item.Message {
+		case "slow query (repeated 2 times)":
+			summary = item
+		case "enabled by rule (repeated 2 times)":
+			rule = true
+		}
+	}
+	if summary == nil || !rule {
+		t.Fatalf("missing summaries")
+	}
+
+	if summary.Message != "slow query (repeated 2 times)" || summary.Prefix != "db" ||
+		summary.Payload["table"] != "users" || summary.Payload["repeated"] != 2 {
+		t.Errorf("invalid summary: [%s] %s %v", summary.Prefix, summary.Message, summary.Payload)
+	}
+
+	if summary.Caller == nil || *summary.Caller != *items[0].Caller {
+		t.Errorf("invalid summary caller: %+v", summary.Caller)
+	}
+}
+
+func TestRateLimit(t *testing.T) {
+	sparalog.InitUnitTest()
+
+	var mu sync.Mutex
+	var items []*logs.Item
+
+	w := writers.NewCallbackWriter(
+		func(item *logs.Item) error {
+			mu.Lock()
+			defer mu.Unlock()
+			items = append(items, item)
+			return nil
+		},
+	)
+	logs.ResetWriters(w)
+
+	sparalog.Start()
+
+	reportInterval := logs.RateLimitReportInterval
+	logs.RateLimitReportInterval = 50 * time.Millisecond
+	defer func() {
+		logs.RateLimitReportInterval = reportInterval
+	}()
+
+	logs.SetRateLimit(logs.InfoLevel, 1, 2)
+
+	for n := 0; n < 5; n++ {
+		logs.Info(fmt.Sprint("info ", n))
+	}
+	logs.Warning("not limited")
+
+	mu.Lock()
+	if len(items) != 3 {
+		t.Errorf("expected 3 items, got %d", len(items))
+	}
+	mu.Unlock()
+
+	time.Sleep(100 * time.Millisecond)
+
+	logs.Info("dropped")
+
+	// I conteggi pendenti vengono riportati allo stop.
+	sparalog.Stop()
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if len(items) != 5 {
+		t.Fatalf("expected 5 items, got %d", len(items))
+	}
+
+	for i, n := range []int{3, 1} {
+		report := items[3+i]
+		if report.Level != logs.InfoLevel || report.Payload["suppressed"] != n {
+			t.Errorf("invalid report: %s %v", report.Message, report.Payload)
+		}
+	}
+}
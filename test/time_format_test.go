@@ -0,0 +1,131 @@
+package test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/modulo-srl/sparalog"
+	"github.com/modulo-srl/sparalog/logs"
+	"github.com/modulo-srl/sparalog/writers"
+)
+
+func TestTimeFormat(t *testing.T) {
+	sparalog.InitUnitTest()
+	defer logs.SetClock(nil)
+	defer logs.SetTimeFormat(logs.DefaultTimeFormat)
+
+	ts := time.Date(2024, 3, 1, 10, 20, 30, 123456789, time.UTC)
+	logs.SetClock(func() time.Time {
+		return ts
+	})
+
+	var last *logs.Item
+
+	w := writers.NewCallbackWriter(
+		func(item *logs.Item) error {
+			last = item
+			return nil
+		},
+	)
+	logs.ResetWriters(w)
+
+	sparalog.Start()
+	defer sparalog.Stop()
+
+	// Orologio iniettato.
+	logs.Info("hello")
+	if !last.Ts.Equal(ts) {
+		t.Fatalf("unexpected ts: %v", last.Ts)
+	}
+
+	if s := last.Timestamp(); s != "2024-03-01 10:20:30.123" {
+		t.Errorf("default timestamp: %s", s)
+	}
+
+	// Formato globale.
+	rome, err := time.LoadLocation("Europe/Rome")
+	if err != nil {
+		t.Skip(err)
+	}
+
+	logs.SetTimeFormat(logs.TimeFormat{Layout: time.RFC3339, Location: rome})
+	if s := last.Timestamp(); s != "2024-03-01T11:20:30+01:00" {
+		t.Errorf("global timestamp: %s", s)
+	}
+
+	s := string(logs.NewTextFormatter().Format(nil, last))
+	if !strings.HasPrefix(s, "2024-03-01T11:20:30+01:00 info: hello") {
+		t.Errorf("text: %s", s)
+	}
+
+	// Formato specifico del formatter.
+	epochs := []struct {
+		precision logs.EpochPrecision
+		ts        string
+	}{
+		{logs.EpochSeconds, "1709288430"},
+		{logs.EpochMillis, "1709288430123"},
+		{logs.EpochMicros, "1709288430123456"},
+		{logs.EpochNanos, "1709288430123456789"},
+	}
+
+	for _, e := range epochs {
+		tf := logs.TimeFormat{Epoch: e.precision}
+
+		s = string((&logs.TextFormatter{Timestamp: true, Time: &tf}).Format(nil, last))
+		if s != e.ts+" info: hello" {
+			t.Errorf("text epoch %d: %s", e.precision, s)
+		}
+
+		s = string((&logs.LogfmtFormatter{Time: &tf}).Format(nil, last))
+		if !strings.HasPrefix(s, "ts="+e.ts+" ") {
+			t.Errorf("logfmt epoch %d: %s", e.precision, s)
+		}
+
+		// I timestamp epoch JSON sono numerici e decodificabili.
+		bb := (&logs.JSONFormatter{Time: &tf}).Format(nil, last)
+		if !strings.HasPrefix(string(bb), `{"ts":`+e.ts+",") {
+			t.Errorf("json epoch %d: %s", e.precision, bb)
+		}
+
+		item, err := logs.NewJSONDecoder(strings.NewReader(string(bb))).Decode()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if d := ts.Sub(item.Ts); d < 0 || d >= time.Second {
+			t.Errorf("json epoch %d decoded as %v", e.precision, item.Ts)
+		}
+	}
+
+	// Formato specifico del writer, senza modificare il formatter condiviso.
+	shared := logs.NewTextFormatter()
+
+	fw := writers.NewCallbackWriter(nil)
+	fw.SetFormatter(shared)
+	fw.SetTimeFormat(logs.TimeFormat{Layout: "15:04:05"})
+
+	if s := string(fw.FormatItem(last)); s != "10:20:30 info: hello" {
+		t.Errorf("writer: %s", s)
+	}
+
+	if shared.Time != nil {
+		t.Error("shared formatter modified")
+	}
+}
+
+func TestClockSurvivesInit(t *testing.T) {
+	ts := time.Date(2024, 3, 1, 10, 20, 30, 0, time.UTC)
+	logs.SetClock(func() time.Time {
+		return ts
+	})
+	defer logs.SetClock(nil)
+
+	// La reinizializzazione del sistema di default (es. da un altro test) non reimposta l'orologio.
+	sparalog.InitUnitTest()
+
+	if item := logs.NewBareItem(logs.InfoLevel, "", "msg"); !item.Ts.Equal(ts) {
+		t.Errorf("clock reset: %v", item.Ts)
+	}
+}
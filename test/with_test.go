@@ -0,0 +1,86 @@
+package test
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/modulo-srl/sparalog"
+	"github.com/modulo-srl/sparalog/logs"
+	"github.com/modulo-srl/sparalog/writers"
+)
+
+func TestLoggerWith(t *testing.T) {
+	sparalog.InitUnitTest()
+
+	var (
+		mu   sync.Mutex
+		last *logs.Item
+	)
+
+	w := writers.NewCallbackWriter(
+		func(item *logs.Item) error {
+			mu.Lock()
+			last = item
+			mu.Unlock()
+			return nil
+		},
+	)
+	logs.ResetWriters(w)
+
+	sparalog.Start()
+	defer sparalog.Stop()
+
+	parent := logs.NewLogger("http")
+	parent.SetPayload("service", "api")
+
+	child := parent.With("request_id", "req-1", 42, "answer", "odd").WithPrefix("users")
+	child.Info("handled")
+
+	if last.Prefix != "http/users" {
+		t.Errorf("unexpected prefix %q", last.Prefix)
+	}
+
+	expected := map[string]any{"service": "api", "request_id": "req-1", "42": "answer", "!BADKEY": "odd"}
+	if len(last.Payload) != len(expected) {
+		t.Errorf("unexpected payload: %v", last.Payload)
+	}
+	for k, v := range expected {
+		if last.Payload[k] != v {
+			t.Errorf("payload %s: expected %v, got %v", k, v, last.Payload[k])
+		}
+	}
+
+	// Il logger padre non viene modificato.
+	parent.Info("parent")
+	if last.Prefix != "http" || len(last.Payload) != 1 {
+		t.Errorf("parent modified: %+v", last)
+	}
+
+	// Le regole di livello del logger registrato valgono anche per i derivati,
+	// anche se impostate successivamente.
+	db := logs.GetLogger("db").With("pool", 1)
+
+	db.Debug("muted")
+	if last.Message == "muted" {
+		t.Error("debug logged without rule")
+	}
+
+	logs.SetLoggersLevels("db=debug")
+	db.Debug("unmuted")
+	if last.Message != "unmuted" || last.Payload["pool"] != 1 {
+		t.Errorf("rule not applied to derived logger: %+v", last)
+	}
+
+	// Loggers derivati concorrenti non interferiscono.
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			l := parent.With("worker", i)
+			l.Info("work")
+			parent.SetPayload("last", i)
+		}(i)
+	}
+	wg.Wait()
+}
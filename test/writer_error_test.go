@@ -43,7 +43,10 @@ func TestWriterError(t *testing.T) {
 
 	logs.Info("test writer error")
 
-	time.Sleep(50 * time.Millisecond)
+	err := logs.Flush(time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
 
 	mu.Lock()
 	defer mu.Unlock()
@@ -88,7 +91,10 @@ func TestAsyncWriterError(t *testing.T) {
 
 	logs.Info("test async writer error")
 
-	time.Sleep(50 * time.Millisecond)
+	err := logs.Flush(time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
 
 	mu.Lock()
 	defer mu.Unlock()
@@ -100,3 +106,110 @@ func TestAsyncWriterError(t *testing.T) {
 		t.Error("too many forwards. deadlock?")
 	}
 }
+
+func TestWriterErrorOnStop(t *testing.T) {
+	sparalog.InitUnitTest()
+
+	var forwardLoggeds int
+	var mu sync.Mutex
+
+	ws := writers.NewCallbackWriter(
+		func(item *logs.Item) error {
+			mu.Lock()
+			defer mu.Unlock()
+			forwardLoggeds++
+			return nil
+		},
+	)
+	logs.ResetLevelWriters(logs.ErrorLevel, ws)
+
+	release := make(chan struct{})
+
+	wa := writers.NewCallbackAsyncWriter(
+		func(item *logs.Item) error {
+			<-release
+			return errors.New("feedback error")
+		},
+	)
+	logs.AddLevelWriter(logs.InfoLevel, wa)
+
+	sparalog.Start()
+
+	logs.Info("test writer error on stop")
+
+	// L'errore viene generato durante lo svuotamento della coda in fase di arresto.
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		close(release)
+	}()
+
+	sparalog.Stop()
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if forwardLoggeds != 1 {
+		t.Errorf("%d forwards logged", forwardLoggeds)
+	}
+}
+
+func TestWriterErrorWhileStopping(t *testing.T) {
+	for run := 0; run < 5; run++ {
+		var mu sync.Mutex
+		forwarded := 0
+
+		sys := logs.NewSystem(writers.NewCallbackWriter(func(item *logs.Item) error {
+			mu.Lock()
+			forwarded++
+			mu.Unlock()
+			return nil
+		}))
+		sys.Logger().AddWriter(writers.NewCallbackWriter(func(item *logs.Item) error {
+			return errors.New("feedback error")
+		}))
+		sys.Start()
+
+		// Writer sincrono che feedbacka inline mentre Stop() è in corso.
+		var wg sync.WaitGroup
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for j := 0; j < 200; j++ {
+					sys.Logger().Warning("message")
+				}
+			}()
+		}
+
+		time.Sleep(time.Millisecond)
+		sys.Stop()
+		wg.Wait()
+	}
+}
+
+func TestWriterFeedbackItem(t *testing.T) {
+	sparalog.InitUnitTest()
+
+	// Payload e stacktrace del sistema di default non vanno applicati ai feedback.
+	logs.SetPayload("default", "payload")
+
+	w := writers.NewCallbackWriter(func(item *logs.Item) error {
+		return errors.New("feedback error")
+	})
+
+	feedback := make(chan *logs.Item, 10)
+	w.SetFeedbackChan(feedback)
+
+	w.Write(logs.NewBareItem(logs.InfoLevel, "", "msg"))
+	w.Feedback(logs.ErrorLevel, "writer feedback")
+
+	for _, msg := range []string{"feedback error", "(log writer) writer feedback"} {
+		item := <-feedback
+		if item.Message != msg || item.Level != logs.ErrorLevel {
+			t.Errorf("unexpected feedback: %+v", item)
+		}
+		if len(item.Payload) != 0 || item.Stack != nil || item.Caller != nil {
+			t.Errorf("feedback bound to the default system: %+v", item)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/modulo-srl/sparalog"
+	"github.com/modulo-srl/sparalog/logs"
+	"github.com/modulo-srl/sparalog/writers"
+)
+
+func TestRemoveWriter(t *testing.T) {
+	sparalog.InitUnitTest()
+
+	var defaultCount, errorCount int
+
+	dw := writers.NewCallbackWriter(
+		func(item *logs.Item) error {
+			defaultCount++
+			return nil
+		},
+	)
+	dw.SetName("default")
+	logs.ResetWriters(dw)
+
+	ew := writers.NewCallbackWriter(
+		func(item *logs.Item) error {
+			errorCount++
+			return nil
+		},
+	)
+	ew.SetName("errors")
+	logs.AddLevelsWriter([]logs.Level{logs.ErrorLevel, logs.WarningLevel}, ew)
+
+	sparalog.Start()
+	defer sparalog.Stop()
+
+	infos := logs.Writers()
+	if len(infos) != 2 {
+		t.Fatalf("expected 2 writers, got %+v", infos)
+	}
+
+	for _, info := range infos {
+		if !info.Started {
+			t.Errorf("writer %s not started", info.Name)
+		}
+
+		switch info.ID {
+		case dw.ID():
+			if info.Name != "default" || len(info.Levels) != len(logs.Levels) ||
+				len(info.DefaultLevels) != len(logs.Levels) {
+				t.Errorf("default writer: %+v", info)
+			}
+		case ew.ID():
+			if info.Name != "errors" || len(info.Levels) != 2 || len(info.DefaultLevels) != 0 {
+				t.Errorf("errors writer: %+v", info)
+			}
+		default:
+			t.Errorf("unexpected writer: %+v", info)
+		}
+	}
+
+	err := logs.RemoveLevelWriter(logs.WarningLevel, ew.ID())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	logs.Warning("warning")
+	logs.Error("error")
+
+	if errorCount != 1 || defaultCount != 2 {
+		t.Errorf("counts: errors %d, default %d", errorCount, defaultCount)
+	}
+
+	err = logs.RemoveWriter(ew.ID())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	logs.Error("error")
+
+	if errorCount != 1 {
+		t.Errorf("removed writer still receiving items")
+	}
+
+	if len(logs.Writers()) != 1 {
+		t.Errorf("expected 1 writer, got %+v", logs.Writers())
+	}
+
+	err = logs.RemoveWriter(ew.ID())
+	if err == nil {
+		t.Error("expected error removing unknown writer")
+	}
+}
@@ -18,6 +18,7 @@ type CallbackWriter struct {
 }
 
 // CallbackWriterCallback define the writer callback.
+// The callback may keep the item: items passed to callback writers are never recycled.
 type CallbackWriterCallback func(*logs.Item) error
 
 // NewCallbackWriter returns a callbackWriter.
@@ -29,6 +30,7 @@ func NewCallbackWriter(callback CallbackWriterCallback) *CallbackWriter {
 	return &w
 }
 
+// Callback writers don't implement logs.PoolSafeWriter: the callback may keep the item.
 func (w *CallbackWriter) Write(item *logs.Item) {
 	w.mu.Lock()
 	defer w.mu.Unlock()
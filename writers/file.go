@@ -28,11 +28,28 @@ func NewFileWriter(filename string) (*FileWriter, error) {
 		return nil, err
 	}
 
+	return &w, nil
+}
+
+// Avvia il writer, riaprendo il file se chiuso da un precedente Stop().
+func (w *FileWriter) Start() error {
+	if w.file == nil {
+		var err error
+
+		w.file, err = os.OpenFile(w.filename, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0666)
+		if err != nil {
+			return err
+		}
+	}
+
 	w.StartQueue(100, w.onQueueItem)
 
-	return &w, nil
+	return nil
 }
 
+// PoolSafe implements logs.PoolSafeWriter.
+func (w *FileWriter) PoolSafe() {}
+
 func (w *FileWriter) Write(item *logs.Item) {
 	w.Enqueue(item)
 }
@@ -41,12 +58,14 @@ func (w *FileWriter) onQueueItem(item *logs.Item) error {
 	//w.mu.Lock()
 	//defer w.mu.Unlock()
 
-	s := item.ToString(true, true)
-
-	_, err := w.file.WriteString(s + "\n")
-	return err
+	return w.WriteFormatted(w.file, item)
 }
 
 func (w *FileWriter) Stop() {
-	w.file.Close()
+	w.StopQueue(3)
+
+	if w.file != nil {
+		w.file.Close()
+		w.file = nil
+	}
 }
@@ -40,19 +40,34 @@ func NewFileRotateWriter(filename string, rotateAfter time.Duration, deleteNotCr
 		return nil, err
 	}
 
+	return &w, nil
+}
+
+// Avvia il writer, riaprendo il file se chiuso da un precedente Stop().
+func (w *FileRotateWriter) Start() error {
+	if w.file == nil {
+		var err error
+
+		w.file, err = os.OpenFile(w.filename, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0666)
+		if err != nil {
+			return err
+		}
+	}
+
 	w.StartQueue(100, w.onQueueItem)
 
-	return &w, nil
+	return nil
 }
 
+// PoolSafe implements logs.PoolSafeWriter.
+func (w *FileRotateWriter) PoolSafe() {}
+
 func (w *FileRotateWriter) Write(item *logs.Item) {
 	w.Enqueue(item)
 }
 
 func (w *FileRotateWriter) onQueueItem(item *logs.Item) error {
-	s := item.ToString(true, true)
-
-	_, err := w.file.WriteString(s + "\n")
+	err := w.WriteFormatted(w.file, item)
 	if err != nil {
 		return err
 	}
@@ -61,7 +76,7 @@ func (w *FileRotateWriter) onQueueItem(item *logs.Item) error {
 		return nil
 	}
 
-	if item.Level <= logs.WarningLevel {
+	if item.Level.Info().Critical {
 		w.critical = true
 	}
 
@@ -80,7 +95,12 @@ func (w *FileRotateWriter) onQueueItem(item *logs.Item) error {
 }
 
 func (w *FileRotateWriter) Stop() {
-	w.file.Close()
+	w.StopQueue(3)
+
+	if w.file != nil {
+		w.file.Close()
+		w.file = nil
+	}
 }
 
 func (w *FileRotateWriter) rotate() error {
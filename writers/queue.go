@@ -0,0 +1,146 @@
+package writers
+
+// Politiche di gestione delle code dei writer asincroni.
+
+import (
+	"time"
+
+	"github.com/modulo-srl/sparalog/logs"
+)
+
+// QueuePolicy definisce il comportamento di Enqueue() quando la coda è piena.
+type QueuePolicy int
+
+const (
+	// Blocca finché la coda non si libera (default).
+	QueueBlock QueuePolicy = iota
+	// Blocca al più per QueueOptions.Timeout, poi scarta l'item.
+	QueueBlockTimeout
+	// Scarta l'item in arrivo.
+	QueueDropNewest
+	// Scarta l'item più vecchio in coda per far posto a quello in arrivo.
+	QueueDropOldest
+	// Blocca per gli item dei livelli QueueOptions.KeepLevels, scarta gli altri.
+	QueueDropNonCritical
+)
+
+const (
+	defaultQueueReportInterval = 10 * time.Second
+)
+
+// Opzioni della coda di un writer asincrono.
+type QueueOptions struct {
+	// Dimensione della coda; se 0 viene usata quella di default del writer.
+	Size int
+
+	Policy QueuePolicy
+
+	// Attesa massima per QueueBlockTimeout.
+	Timeout time.Duration
+
+	// Livelli mai scartati da QueueDropNonCritical;
+	// se nil vengono usati logs.CriticalLevels.
+	KeepLevels []logs.Level
+
+	// Intervallo con cui il numero di item scartati viene riportato
+	// tramite Feedback(); se 0 viene usato un intervallo di 10 secondi.
+	ReportInterval time.Duration
+}
+
+// Imposta le opzioni della coda.
+// Va chiamata prima dello Start() del writer.
+func (w *Writer) SetQueueOptions(opts QueueOptions) {
+	w.queueOptions = opts
+}
+
+// Accoda l'item secondo la politica impostata.
+// Va invocata con w.queueMu acquisito in lettura.
+// Il riferimento all'item acquisito per la coda viene rilasciato se l'item viene scartato.
+func (w *Writer) enqueue(item *logs.Item) {
+	opts := &w.queueOptions
+
+	item.Retain()
+	w.queuePending.Add(1)
+
+	switch opts.Policy {
+	case QueueBlockTimeout:
+		t := time.NewTimer(opts.Timeout)
+		defer t.Stop()
+
+		select {
+		case w.queue <- item:
+		case <-t.C:
+			w.drop(item)
+		}
+
+	case QueueDropNewest:
+		select {
+		case w.queue <- item:
+		default:
+			w.drop(item)
+		}
+
+	case QueueDropOldest:
+		for {
+			select {
+			case w.queue <- item:
+				return
+			default:
+			}
+
+			select {
+			case old := <-w.queue:
+				w.drop(old)
+			default:
+			}
+		}
+
+	case QueueDropNonCritical:
+		if w.isKeepLevel(item.Level) {
+			w.queue <- item
+			return
+		}
+
+		select {
+		case w.queue <- item:
+		default:
+			w.drop(item)
+		}
+
+	default:
+		w.queue <- item
+	}
+}
+
+// Scarta un item accodato o in accodamento, rilasciandone il riferimento.
+func (w *Writer) drop(item *logs.Item) {
+	w.queueDropped.Add(1)
+	item.Release()
+	w.queuePending.Add(-1)
+}
+
+func (w *Writer) isKeepLevel(level logs.Level) bool {
+	levels := w.queueOptions.KeepLevels
+	if levels == nil {
+		// Equivale a logs.CriticalLevels, senza leggere la variabile esportata.
+		return level.Info().Critical
+	}
+
+	for _, l := range levels {
+		if l == level {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Riporta tramite Feedback() il numero di item scartati dall'ultimo report.
+func (w *Writer) reportDropped() {
+	n := w.queueDropped.Swap(0)
+	if n == 0 {
+		return
+	}
+
+	w.Feedbackf(logs.WarningLevel, "queue full: %d items dropped", n)
+}
@@ -0,0 +1,64 @@
+package writers
+
+// Writer that forwards items to a log/slog handler.
+
+import (
+	"context"
+	"log/slog"
+	"sort"
+
+	"github.com/modulo-srl/sparalog/logs"
+)
+
+type SlogWriter struct {
+	Writer
+
+	handler slog.Handler
+}
+
+// NewSlogWriter returns a writer that forwards every item to a slog.Handler.
+// The prefix, the payload and the stack trace become record attributes.
+func NewSlogWriter(h slog.Handler) *SlogWriter {
+	w := SlogWriter{
+		handler: h,
+	}
+
+	return &w
+}
+
+// PoolSafe implements logs.PoolSafeWriter.
+func (w *SlogWriter) PoolSafe() {}
+
+func (w *SlogWriter) Write(item *logs.Item) {
+	ctx := context.Background()
+	level := logs.SlogLevel(item.Level)
+
+	if !w.handler.Enabled(ctx, level) {
+		return
+	}
+
+	r := slog.NewRecord(item.Ts, level, item.Message, 0)
+
+	if item.Prefix != "" {
+		r.AddAttrs(slog.String("prefix", item.Prefix))
+	}
+
+	keys := make([]string, 0, len(item.Payload))
+	for k := range item.Payload {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		r.AddAttrs(slog.Any(k, item.Payload[k]))
+	}
+
+	if item.Stack != nil {
+		r.AddAttrs(slog.String("stacktrace", item.Stack.String()))
+	}
+
+	err := w.handler.Handle(ctx, r)
+	if err != nil {
+		w.FeedbackError(err)
+	}
+}
@@ -1,7 +1,6 @@
 package writers
 
 import (
-	"fmt"
 	"os"
 	"sync"
 
@@ -21,16 +20,17 @@ func NewStdoutWriter() *StdoutWriter {
 	return &w
 }
 
+// PoolSafe implements logs.PoolSafeWriter.
+func (w *StdoutWriter) PoolSafe() {}
+
 func (w *StdoutWriter) Write(item *logs.Item) {
 	w.mu.Lock()
 	defer w.mu.Unlock()
 
-	s := item.ToString(true, true)
-
-	if item.Level <= logs.WarningLevel {
-		fmt.Fprintln(os.Stderr, s)
+	if item.Level.Info().Stderr {
+		w.WriteFormatted(os.Stderr, item)
 		return
 	}
 
-	fmt.Println(s)
+	w.WriteFormatted(os.Stdout, item)
 }
@@ -19,6 +19,9 @@ type SyslogWriter struct {
 func NewSyslogWriter(tag string) *SyslogWriter {
 	w := SyslogWriter{}
 
+	// Il timestamp viene già aggiunto da syslog.
+	w.SetFormatter(&logs.TextFormatter{StackTrace: true, Payload: true})
+
 	var err error
 	w.sys, err = syslog.New(syslog.LOG_INFO, tag)
 
@@ -29,20 +32,29 @@ func NewSyslogWriter(tag string) *SyslogWriter {
 	return &w
 }
 
-func (w *SyslogWriter) Write(item *logs.Item) {
-	s := item.ToString(false, true)
+// PoolSafe implements logs.PoolSafeWriter.
+func (w *SyslogWriter) PoolSafe() {}
 
-	switch item.Level {
-	case logs.DebugLevel:
-		w.sys.Debug(s)
-	case logs.InfoLevel:
-		w.sys.Info(s)
-	case logs.WarningLevel:
-		w.sys.Warning(s)
-	case logs.ErrorLevel:
-		w.sys.Err(s)
-	case logs.FatalLevel:
+func (w *SyslogWriter) Write(item *logs.Item) {
+	s := string(w.FormatItem(item))
+
+	switch item.Level.Info().SyslogSeverity {
+	case 0:
+		w.sys.Emerg(s)
+	case 1:
+		w.sys.Alert(s)
+	case 2:
 		w.sys.Crit(s)
+	case 3:
+		w.sys.Err(s)
+	case 4:
+		w.sys.Warning(s)
+	case 5:
+		w.sys.Notice(s)
+	case 6:
+		w.sys.Info(s)
+	default:
+		w.sys.Debug(s)
 	}
 }
 
@@ -22,6 +22,7 @@ type TcpWriter struct {
 
 	listener net.Listener
 	quitCh   chan bool
+	stopOnce sync.Once
 	//connsWG  sync.WaitGroup
 
 	mu          sync.RWMutex
@@ -87,13 +88,19 @@ func (w *TcpWriter) Start() error {
 	return nil
 }
 
+// Le chiamate successive alla prima non hanno effetto.
 func (w *TcpWriter) Stop() {
-	w.StopQueue(1)
+	w.stopOnce.Do(func() {
+		w.StopQueue(1)
 
-	close(w.quitCh)
-	//w.connsWG.Wait()
+		close(w.quitCh)
+		//w.connsWG.Wait()
+	})
 }
 
+// Implementa logs.PoolSafeWriter.
+func (w *TcpWriter) PoolSafe() {}
+
 func (w *TcpWriter) Write(item *logs.Item) {
 	w.mu.RLock()
 	defer w.mu.RUnlock()
@@ -113,7 +120,7 @@ func (w *TcpWriter) onQueueItem(item *logs.Item) error {
 		return nil
 	}
 
-	s := item.ToString(true, true) + "\n"
+	s := string(w.FormatItem(item)) + "\n"
 
 	if w.filter != "" {
 		i := strings.Index(s, w.filter)
@@ -3,7 +3,9 @@ package writers
 import (
 	"crypto/rand"
 	"fmt"
+	"io"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/modulo-srl/sparalog/logs"
@@ -11,58 +13,228 @@ import (
 
 // Implementa i metodi base.
 type Writer struct {
-	id string
+	idOnce sync.Once
+	id     string
 
+	name string
+
+	formatter logs.Formatter
+
+	// Protetto da feedbackMu: un send in corso impedisce il distacco del canale.
+	feedbackMu sync.RWMutex
 	feedbackCh chan *logs.Item
 
-	queue   chan *logs.Item
-	queueWG sync.WaitGroup
+	queue        chan *logs.Item
+	queueWG      sync.WaitGroup
+	queueOptions QueueOptions
+	queueDropped atomic.Int64
+
+	// Item scartati perché ricevuti a coda non avviata o già chiusa,
+	// riportati al successivo StartQueue().
+	queueRejected atomic.Int64
+
+	// Item accodati o in elaborazione, vedi Flush().
+	queuePending atomic.Int64
+
+	// Protegge la chiusura della coda dagli Enqueue concorrenti.
+	queueMu     sync.RWMutex
+	queueClosed bool
 }
 
+// Ritorna l'identificativo univoco del writer, generato casualmente al primo utilizzo.
 func (w *Writer) ID() string {
-	if w.id == "" {
+	w.idOnce.Do(func() {
 		bb := make([]byte, 8)
 		rand.Read(bb)
 		w.id = fmt.Sprintf("%X", bb)
-	}
+	})
 
 	return w.id
 }
 
+// Ritorna il nome descrittivo del writer.
+func (w *Writer) Name() string {
+	return w.name
+}
+
+// Imposta un nome descrittivo del writer, visibile in logs.Writers().
+// Va chiamata prima di associare il writer.
+func (w *Writer) SetName(name string) {
+	w.name = name
+}
+
 func (w *Writer) Start() error { return nil }
 func (w *Writer) Stop()        {}
 
+// Formatter usato quando non ne è stato impostato uno specifico.
+var defaultFormatter = logs.NewTextFormatter()
+
+// Imposta il formatter usato dal writer per serializzare gli item.
+// Va chiamata prima dello Start() del writer.
+func (w *Writer) SetFormatter(f logs.Formatter) {
+	w.formatter = f
+}
+
+// Imposta il formato del timestamp del writer, sostituendo il formatter con una sua copia
+// che lo adotta (il formatter originale, eventualmente condiviso, non viene modificato).
+// I formatter che non supportano logs.TimeFormat (vedi WithTimeFormat() dei formatter di logs)
+// vengono lasciati invariati.
+// Va chiamata dopo SetFormatter() e prima dello Start() del writer.
+func (w *Writer) SetTimeFormat(tf logs.TimeFormat) {
+	f := w.formatter
+	if f == nil {
+		f = defaultFormatter
+	}
+
+	if tff, ok := f.(interface {
+		WithTimeFormat(logs.TimeFormat) logs.Formatter
+	}); ok {
+		w.formatter = tff.WithTimeFormat(tf)
+	}
+}
+
+// Formatta l'item con il formatter impostato,
+// o con il formatter testuale di default se non impostato.
+func (w *Writer) FormatItem(item *logs.Item) []byte {
+	f := w.formatter
+	if f == nil {
+		f = defaultFormatter
+	}
+
+	return f.Format(nil, item)
+}
+
+// Buffer di formattazione riutilizzati da WriteFormatted().
+var buffersPool = sync.Pool{
+	New: func() any {
+		bb := make([]byte, 0, 512)
+		return &bb
+	},
+}
+
+// Buffer più grandi non vengono restituiti al pool, per non trattenere memoria
+// dopo un item eccezionalmente grande.
+const maxPooledBufferSize = 64 << 10
+
+// Formatta l'item seguito da newline in un buffer riutilizzato e lo scrive su out,
+// senza allocazioni nel caso comune.
+func (w *Writer) WriteFormatted(out io.Writer, item *logs.Item) error {
+	f := w.formatter
+	if f == nil {
+		f = defaultFormatter
+	}
+
+	pbb := buffersPool.Get().(*[]byte)
+
+	bb := append(f.Format((*pbb)[:0], item), '\n')
+	_, err := out.Write(bb)
+
+	if cap(bb) <= maxPooledBufferSize {
+		*pbb = bb
+		buffersPool.Put(pbb)
+	}
+
+	return err
+}
+
 type OnItemFunc func(*logs.Item) error
 
 // Avvia il worker di gestione della coda,
 // invocando la callback OnItemFunc() per ogni item da processare.
 // Se la callback ritorna errore questo viene feedbackato al writer di default
 // del rispettivo livello.
+// Invocabile nuovamente dopo StopQueue(), per riavviare il writer con una nuova coda.
+// Gli item scartati mentre la coda non era attiva vengono riportati tramite Feedback().
+// - queueSize: dimensione di default della coda, se non impostata con SetQueueOptions().
 func (w *Writer) StartQueue(queueSize int, f OnItemFunc) {
-	w.queue = make(chan *logs.Item, queueSize)
+	if w.queueOptions.Size > 0 {
+		queueSize = w.queueOptions.Size
+	}
+
+	// Il worker legge solo la propria coda: un worker distaccato da uno StopQueue()
+	// scaduto non consuma quella nuova.
+	queue := make(chan *logs.Item, queueSize)
+
+	w.queueMu.Lock()
+	w.queue = queue
+	w.queueClosed = false
+	w.queuePending.Store(0)
+	w.queueMu.Unlock()
+
+	if n := w.queueRejected.Swap(0); n > 0 {
+		w.Feedbackf(logs.WarningLevel, "queue not running: %d items dropped", n)
+	}
 
 	w.queueWG.Add(1)
 	go func() {
-		for item := range w.queue {
-			err := f(item)
-			if err != nil {
-				//fmt.Println(err)
-				w.FeedbackError(err)
+		defer w.queueWG.Done()
+
+		// Report periodico degli item scartati, per le politiche che ne prevedono.
+		var report <-chan time.Time
+		if w.queueOptions.Policy != QueueBlock {
+			interval := w.queueOptions.ReportInterval
+			if interval <= 0 {
+				interval = defaultQueueReportInterval
 			}
+
+			ticker := time.NewTicker(interval)
+			defer ticker.Stop()
+			report = ticker.C
 		}
 
-		w.queueWG.Done()
+		for {
+			select {
+			case item, ok := <-queue:
+				if !ok {
+					return
+				}
+
+				err := f(item)
+				if err != nil {
+					w.FeedbackError(err)
+				}
+
+				item.Release()
+				w.queuePending.Add(-1)
+
+			case <-report:
+				w.reportDropped()
+			}
+		}
 	}()
 }
 
-// Ritorna immediatamente.
+// Accoda l'item e ritorna immediatamente; se la coda è piena si comporta
+// secondo la politica impostata con SetQueueOptions() (di default blocca).
+// Gli item accodati prima dello StartQueue() o dopo lo StopQueue() vengono scartati,
+// e riportati tramite Feedback() al successivo StartQueue().
+// Mentre è in coda viene detenuto un riferimento all'item (vedi logs.Item.Retain()),
+// rilasciato dopo l'invocazione della OnItemFunc o quando l'item viene scartato.
 func (w *Writer) Enqueue(item *logs.Item) {
-	w.queue <- item
+	w.queueMu.RLock()
+	defer w.queueMu.RUnlock()
+
+	if w.queue == nil || w.queueClosed {
+		w.queueRejected.Add(1)
+		return
+	}
+
+	w.enqueue(item)
 }
 
 // Finisce di consegnare gli item rimanenti in coda e termina.
+// Gli item scartati per coda piena non ancora riportati, e quelli non consegnati
+// allo scadere del timeout, vengono riportati con un unico Feedback().
+// Le chiamate successive alla prima non hanno effetto.
 func (w *Writer) StopQueue(timeoutSecs int) {
+	w.queueMu.Lock()
+	if w.queueClosed || w.queue == nil {
+		w.queueMu.Unlock()
+		return
+	}
+	w.queueClosed = true
 	close(w.queue)
+	w.queueMu.Unlock()
 
 	ch := make(chan struct{})
 	go func() {
@@ -70,41 +242,105 @@ func (w *Writer) StopQueue(timeoutSecs int) {
 		close(ch)
 	}()
 
+	var lost int64
+	timedOut := false
+
 	select {
 	case <-ch:
 	case <-time.After(time.Second * time.Duration(timeoutSecs)):
+		timedOut = true
+		lost = w.queuePending.Load()
+	}
+
+	if dropped := w.queueDropped.Swap(0); dropped > 0 || lost > 0 {
+		w.Feedbackf(logs.WarningLevel, "queue stopped: %d items dropped, %d not delivered", dropped, lost)
+	}
+
+	if timedOut {
+		// Il worker è ancora attivo: viene distaccato dal canale di feedback,
+		// che il dispatcher chiude dopo aver stoppato i writer.
+		w.feedbackMu.Lock()
+		w.feedbackCh = nil
+		w.feedbackMu.Unlock()
+	}
+}
+
+// Attende che tutti gli item accodati siano stati elaborati, al più per timeout;
+// ritorna false se il timeout è scaduto prima.
+// Per i writer sincroni (che non usano la coda) ritorna immediatamente true.
+func (w *Writer) Flush(timeout time.Duration) bool {
+	deadline := time.Now().Add(timeout)
+
+	for w.queuePending.Load() > 0 {
+		if time.Now().After(deadline) {
+			return false
+		}
+
+		time.Sleep(time.Millisecond)
 	}
+
+	return true
 }
 
 // Imposta il canale interno di feeback.
-// Viene invocata dal logger quando imposta un nuovo writer per un certo livello.
+// Viene invocata dal logger quando imposta un nuovo writer per un certo livello,
+// e con nil all'arresto del dispatcher, prima della chiusura del canale.
 func (w *Writer) SetFeedbackChan(ch chan *logs.Item) {
+	w.feedbackMu.Lock()
+	defer w.feedbackMu.Unlock()
+
 	w.feedbackCh = ch
 }
 
 // Genera un item e lo invia al writer di default del rispettivo livello.
+// Gli item di feedback non ereditano payload né impostazioni dal sistema di default.
 func (w *Writer) Feedback(level logs.Level, args ...any) {
-	if w.feedbackCh == nil {
+	if !w.hasFeedback() {
 		return
 	}
 
-	w.feedbackCh <- logs.NewItem(level, "(log writer) ", fmt.Sprint(args...))
+	w.sendFeedback(logs.NewBareItem(level, "", "(log writer) "+fmt.Sprint(args...)))
 }
 
 // Genera un item e lo invia al writer di default del rispettivo livello.
 func (w *Writer) Feedbackf(level logs.Level, format string, args ...any) {
-	if w.feedbackCh == nil {
+	if !w.hasFeedback() {
 		return
 	}
 
-	w.feedbackCh <- logs.NewItem(level, "(log writer) ", fmt.Sprintf(format, args...))
+	w.sendFeedback(logs.NewBareItem(level, "", "(log writer) "+fmt.Sprintf(format, args...)))
 }
 
 // Incapsula e invia un errore al writer di default del livello ErrorLevel.
 func (w *Writer) FeedbackError(err error) {
+	if !w.hasFeedback() {
+		return
+	}
+
+	item := logs.NewBareItem(logs.ErrorLevel, "", err.Error())
+	item.SetError(err)
+
+	w.sendFeedback(item)
+}
+
+// Ritorna true se il writer ha un canale di feedback.
+func (w *Writer) hasFeedback() bool {
+	w.feedbackMu.RLock()
+	defer w.feedbackMu.RUnlock()
+
+	return w.feedbackCh != nil
+}
+
+// Invia un item sul canale di feedback.
+// Il dispatcher chiude il canale solo dopo aver distaccato i writer con SetFeedbackChan(nil),
+// che attende il termine degli invii in corso.
+func (w *Writer) sendFeedback(item *logs.Item) {
+	w.feedbackMu.RLock()
+	defer w.feedbackMu.RUnlock()
+
 	if w.feedbackCh == nil {
 		return
 	}
 
-	w.feedbackCh <- logs.NewErrorItem(err)
+	w.feedbackCh <- item
 }